@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// splitWords splits a string into words at non alphanumeric characters and at
+// case changes, so "HTTPServer", "http_server" and "http server" all give the
+// words "HTTP" and "Server" (or their lower case forms).
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if r == '\'' || r == '’' {
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+func upperFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+func joinWords(s, sep string, transform func(i int, word string) string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = transform(i, word)
+	}
+	return strings.Join(words, sep)
+}
+
+func camelCase(s string) string {
+	return joinWords(s, "", func(i int, word string) string {
+		if i == 0 {
+			return strings.ToLower(word)
+		}
+		return upperFirst(strings.ToLower(word))
+	})
+}
+
+func pascalCase(s string) string {
+	return joinWords(s, "", func(_ int, word string) string {
+		return upperFirst(strings.ToLower(word))
+	})
+}
+
+func snakeCase(s string) string {
+	return joinWords(s, "_", func(_ int, word string) string {
+		return strings.ToLower(word)
+	})
+}
+
+func kebabCase(s string) string {
+	return joinWords(s, "-", func(_ int, word string) string {
+		return strings.ToLower(word)
+	})
+}
+
+func screamingSnake(s string) string {
+	return joinWords(s, "_", func(_ int, word string) string {
+		return strings.ToUpper(word)
+	})
+}
+
+// title capitalizes the first letter of every word using Unicode title
+// casing rules and lower cases the rest.
+func title(s string) string {
+	return cases.Title(language.Und).String(s)
+}
+
+// initials returns the upper cased first letter of every word, split like
+// the other case helpers split them, so "HTTPServer" gives "HS".
+func initials(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		for _, r := range word {
+			b.WriteRune(unicode.ToUpper(r))
+			break
+		}
+	}
+	return b.String()
+}
+
+// transliterations lists letters which are not decomposed into a base letter
+// and a combining mark by Unicode normalization.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'ø': "o", 'Ø': "O", 'œ': "oe", 'Œ': "OE",
+	'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "TH", 'ł': "l",
+	'Ł': "L", 'ı': "i", 'ħ': "h", 'Ħ': "H",
+}
+
+// transliterate replaces accented letters with their ASCII base letters.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// slugify converts a string to a lower case, URL friendly form: accented
+// letters are transliterated and everything else than ASCII letters and
+// digits becomes a single dash.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(transliterate(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		if r != '\'' && r != '’' {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+var irregularPlurals = map[string]string{
+	"person": "people", "man": "men", "woman": "women", "child": "children",
+	"mouse": "mice", "goose": "geese", "foot": "feet", "tooth": "teeth",
+	"ox": "oxen", "index": "indices", "matrix": "matrices", "vertex": "vertices",
+	"criterion": "criteria", "datum": "data", "analysis": "analyses",
+	"knife": "knives", "wife": "wives", "life": "lives", "leaf": "leaves",
+	"half": "halves", "wolf": "wolves", "shelf": "shelves", "calf": "calves",
+	"hero": "heroes", "potato": "potatoes", "tomato": "tomatoes", "echo": "echoes",
+	"quiz": "quizzes", "status": "statuses", "bus": "buses", "alias": "aliases",
+	"movie": "movies", "cookie": "cookies",
+}
+
+var uncountables = map[string]bool{
+	"sheep": true, "fish": true, "deer": true, "series": true, "species": true,
+	"information": true, "equipment": true, "news": true, "software": true,
+	"hardware": true, "metadata": true, "rice": true, "money": true,
+}
+
+// matchCase returns the inflected lower case word with the case of the
+// original word: the letters the inflection kept are copied from the
+// original, so "Account" gives "Accounts" and "Person" "People", and the new
+// letters are upper case if the original is upper case.
+func matchCase(original, word string) string {
+	if len(original) > 1 && original == strings.ToUpper(original) {
+		return strings.ToUpper(word)
+	}
+	orig, inflected := []rune(original), []rune(word)
+	kept := 0
+	for kept < len(orig) && kept < len(inflected) && unicode.ToLower(orig[kept]) == inflected[kept] {
+		kept++
+	}
+	return string(orig[:kept]) + string(inflected[kept:])
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+func pluralWord(word string) string {
+	lower := strings.ToLower(word)
+	if lower == "" || uncountables[lower] {
+		return word
+	}
+	if plural, ok := irregularPlurals[lower]; ok {
+		return matchCase(word, plural)
+	}
+
+	var plural string
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !isVowel(lower[len(lower)-2]):
+		plural = lower[:len(lower)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		plural = lower + "es"
+	default:
+		plural = lower + "s"
+	}
+	return matchCase(word, plural)
+}
+
+func singularWord(word string) string {
+	lower := strings.ToLower(word)
+	if lower == "" || uncountables[lower] {
+		return word
+	}
+	for singular, plural := range irregularPlurals {
+		if plural == lower {
+			return matchCase(word, singular)
+		}
+	}
+
+	var singular string
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		singular = lower[:len(lower)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		singular = lower[:len(lower)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		singular = lower
+	case strings.HasSuffix(lower, "s"):
+		singular = lower[:len(lower)-1]
+	default:
+		singular = lower
+	}
+	return matchCase(word, singular)
+}
+
+// acronymPlural matches an acronym with a plural "s", like "APIs".
+var acronymPlural = regexp.MustCompile(`\p{Lu}{2,}s$`)
+
+// lastSegment returns the bounds of the last word of s as splitWords splits
+// it, so the last word of "user account" is "account" and of "UserAccount"
+// "Account". An acronym with a plural "s", like the "APIs" of "listAPIs", is
+// one word.
+func lastSegment(s string) (int, int) {
+	end := len(s)
+	for end > 0 && !isWordByte(s[end-1]) {
+		end--
+	}
+	start := end
+	for start > 0 && isWordByte(s[start-1]) {
+		start--
+	}
+	run := s[start:end]
+	if loc := acronymPlural.FindStringIndex(run); loc != nil {
+		return start + loc[0], end
+	}
+	if words := splitWords(run); len(words) > 0 && strings.HasSuffix(run, words[len(words)-1]) {
+		return end - len(words[len(words)-1]), end
+	}
+	return start, end
+}
+
+// isAcronym reports whether the last word of s is an acronym, written in
+// upper case in a string with lower case letters, like the "ID" of "userID",
+// or a short upper case word on its own, like "API". The words of an upper
+// case identifier like "USER_ACCOUNT" are not acronyms.
+func isAcronym(s, word string) bool {
+	if utf8.RuneCountInString(word) < 2 || word != strings.ToUpper(word) {
+		return false
+	}
+	if strings.ToUpper(s) != s {
+		return true
+	}
+	return len(splitWords(s)) == 1 && utf8.RuneCountInString(word) <= 4
+}
+
+// lastWordApply applies fn to the last word of s, keeping the rest of s as
+// it is, so "user account" becomes "user accounts" and "UserAccount"
+// "UserAccounts".
+func lastWordApply(s string, fn func(s, word string) string) string {
+	start, end := lastSegment(s)
+	if start == end {
+		return s
+	}
+	return s[:start] + fn(s, s[start:end]) + s[end:]
+}
+
+func isWordByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b >= 0x80
+}
+
+// pluralize returns the English plural form of a word, called as (word) or
+// (count, word), in which case the word is returned unchanged for a count
+// of 1.
+func pluralize(args ...any) (string, error) {
+	var word string
+	switch len(args) {
+	case 1:
+		word, _ = toString(args[0])
+	case 2:
+		count, err := toInt(args[0])
+		if err != nil {
+			return "", fmt.Errorf("pluralize: invalid count: %w", err)
+		}
+		word, _ = toString(args[1])
+		if count == 1 || count == -1 {
+			return word, nil
+		}
+	default:
+		return "", fmt.Errorf("pluralize: expected 1 or 2 arguments, got %d", len(args))
+	}
+	return lastWordApply(word, pluralLastWord), nil
+}
+
+// pluralLastWord returns the plural of the last word of s. Acronyms get a
+// lower case "s": "APIs", "userIDs".
+func pluralLastWord(s, word string) string {
+	if isAcronym(s, word) {
+		return word + "s"
+	}
+	return pluralWord(word)
+}
+
+// singularize returns the English singular form of a word.
+func singularize(s string) string {
+	return lastWordApply(s, func(_, word string) string {
+		if acronymPlural.MatchString(word) {
+			return strings.TrimSuffix(word, "s")
+		}
+		return singularWord(word)
+	})
+}
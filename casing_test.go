@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		input                                  string
+		camel, pascal, snake, kebab, screaming string
+	}{
+		{"hello world", "helloWorld", "HelloWorld", "hello_world", "hello-world", "HELLO_WORLD"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server", "HTTP_SERVER"},
+		{"user_id", "userId", "UserId", "user_id", "user-id", "USER_ID"},
+		{"getUser2FA", "getUser2Fa", "GetUser2Fa", "get_user2_fa", "get-user2-fa", "GET_USER2_FA"},
+		{"  --already-kebab--  ", "alreadyKebab", "AlreadyKebab", "already_kebab", "already-kebab", "ALREADY_KEBAB"},
+		{"Árvíztűrő tükörfúrógép", "árvíztűrőTükörfúrógép", "ÁrvíztűrőTükörfúrógép", "árvíztűrő_tükörfúrógép", "árvíztűrő-tükörfúrógép", "ÁRVÍZTŰRŐ_TÜKÖRFÚRÓGÉP"},
+		{"don't stop", "dontStop", "DontStop", "dont_stop", "dont-stop", "DONT_STOP"},
+		{"", "", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			for name, result := range map[string][2]string{
+				"camelCase":      {camelCase(tt.input), tt.camel},
+				"pascalCase":     {pascalCase(tt.input), tt.pascal},
+				"snakeCase":      {snakeCase(tt.input), tt.snake},
+				"kebabCase":      {kebabCase(tt.input), tt.kebab},
+				"screamingSnake": {screamingSnake(tt.input), tt.screaming},
+			} {
+				if result[0] != result[1] {
+					t.Errorf("%s: expected %q, got %q", name, result[1], result[0])
+				}
+			}
+		})
+	}
+}
+
+func TestTitleSlugifyInitials(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(string) string
+		input    string
+		expected string
+	}{
+		{"title", title, "hello wORLD", "Hello World"},
+		{"title unicode", title, "élő ÁRVÍZ", "Élő Árvíz"},
+		{"title digraph", title, "ǆemal", "ǅemal"},
+		{"slugify", slugify, "Hello, World!", "hello-world"},
+		{"slugify accents", slugify, "Árvíztűrő Tükörfúrógép", "arvizturo-tukorfurogep"},
+		{"slugify special letters", slugify, "Straße Ørsted Łódź", "strasse-orsted-lodz"},
+		{"slugify apostrophe", slugify, "Don't panic", "dont-panic"},
+		{"slugify non latin", slugify, "日本 test", "test"},
+		{"initials", initials, "John Ronald Reuel Tolkien", "JRRT"},
+		{"initials unicode", initials, "ádám éva", "ÁÉ"},
+		{"initials dashes", initials, "jean-luc picard", "JLP"},
+		{"initials camel case", initials, "HTTPServer", "HS"},
+		{"initials snake case", initials, "user_account_id", "UAI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.fn(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPluralizeSingularize(t *testing.T) {
+	tests := []struct {
+		singular, plural string
+	}{
+		{"user", "users"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"box", "boxes"},
+		{"class", "classes"},
+		{"branch", "branches"},
+		{"person", "people"},
+		{"Child", "Children"},
+		{"knife", "knives"},
+		{"sheep", "sheep"},
+		{"user account", "user accounts"},
+		{"order_item", "order_items"},
+		{"UserAccount", "UserAccounts"},
+		{"userId", "userIds"},
+		{"userID", "userIDs"},
+		{"API", "APIs"},
+		{"listAPI", "listAPIs"},
+		{"user_category", "user_categories"},
+		{"UserCategory", "UserCategories"},
+		{"USER_ACCOUNT", "USER_ACCOUNTS"},
+		{"CATEGORY", "CATEGORIES"},
+		{"SalesPerson", "SalesPeople"},
+		{"HTTPServer", "HTTPServers"},
+		{"version2", "version2s"},
+		{"UserV2", "UserV2s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			plural, err := pluralize(tt.singular)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plural != tt.plural {
+				t.Errorf("pluralize: expected %q, got %q", tt.plural, plural)
+			}
+			if singular := singularize(tt.plural); singular != tt.singular {
+				t.Errorf("singularize: expected %q, got %q", tt.singular, singular)
+			}
+		})
+	}
+
+	t.Run("with count", func(t *testing.T) {
+		for count, expected := range map[int]string{0: "items", 1: "item", 2: "items"} {
+			result, err := pluralize(count, "item")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != expected {
+				t.Errorf("count %d: expected %q, got %q", count, expected, result)
+			}
+		}
+		if _, err := pluralize("x", "item"); err == nil {
+			t.Errorf("expected error for invalid count")
+		}
+	})
+}
+
+func TestCaseConversionTemplate(t *testing.T) {
+	var buf strings.Builder
+	tpl := `type {{ pascalCase .name }} struct{} // table {{ .name | snakeCase | pluralize }}, {{ len .items }} {{ pluralize (len .items) "item" }}`
+	data := map[string]any{"name": "order item", "items": []any{1}}
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "type OrderItem struct{} // table order_items, 1 item"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+func parsePrefix(name, s string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%s: invalid CIDR prefix '%s': %w", name, s, err)
+	}
+	return p.Masked(), nil
+}
+
+func parseIP(name, s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%s: invalid IP address '%s': %w", name, s, err)
+	}
+	return addr, nil
+}
+
+func addrToInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+// intToAddr converts a number back to an address of the same family as
+// like. It fails if the number is out of the address space.
+func intToAddr(n *big.Int, like netip.Addr) (netip.Addr, bool) {
+	size := like.BitLen() / 8
+	if n.Sign() < 0 || n.BitLen() > like.BitLen() {
+		return netip.Addr{}, false
+	}
+	addr, ok := netip.AddrFromSlice(n.FillBytes(make([]byte, size)))
+	return addr, ok
+}
+
+// blockSize returns the number of addresses in a prefix of the given length.
+func blockSize(bits, prefixLen int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits-prefixLen))
+}
+
+// cidrHost returns the address of the given host number in a prefix, as
+// Terraform's cidrhost does. Negative host numbers count from the end of the
+// prefix, -1 is the last address.
+func cidrHost(hostnum any, prefix string) (string, error) {
+	n, err := toInt(hostnum)
+	if err != nil {
+		return "", fmt.Errorf("cidrHost: invalid host number: %w", err)
+	}
+	p, err := parsePrefix("cidrHost", prefix)
+	if err != nil {
+		return "", err
+	}
+
+	size := blockSize(p.Addr().BitLen(), p.Bits())
+	num := big.NewInt(int64(n))
+	if n < 0 {
+		num.Add(num, size)
+	}
+	if num.Sign() < 0 || num.Cmp(size) >= 0 {
+		return "", fmt.Errorf("cidrHost: prefix %s has no host number %d, it has %s addresses", p, n, size)
+	}
+
+	addr, _ := intToAddr(num.Add(num, addrToInt(p.Addr())), p.Addr())
+	return addr.String(), nil
+}
+
+// cidrSubnet returns the netnum-th subnet of a prefix, with newbits added to
+// the prefix length, as Terraform's cidrsubnet does.
+func cidrSubnet(newbits, netnum any, prefix string) (string, error) {
+	bits, num, err := toIntPair(newbits, netnum)
+	if err != nil {
+		return "", fmt.Errorf("cidrSubnet: %w", err)
+	}
+	p, err := parsePrefix("cidrSubnet", prefix)
+	if err != nil {
+		return "", err
+	}
+
+	length := p.Bits() + bits
+	if bits < 0 || length > p.Addr().BitLen() {
+		return "", fmt.Errorf("cidrSubnet: can't add %d bits to prefix %s", bits, p)
+	}
+	count := blockSize(length, p.Bits())
+	if num < 0 || big.NewInt(int64(num)).Cmp(count) >= 0 {
+		return "", fmt.Errorf("cidrSubnet: prefix %s has no subnet %d of %d new bits, it has %s", p, num, bits, count)
+	}
+
+	offset := new(big.Int).Mul(big.NewInt(int64(num)), blockSize(p.Addr().BitLen(), length))
+	addr, _ := intToAddr(offset.Add(offset, addrToInt(p.Addr())), p.Addr())
+	return netip.PrefixFrom(addr, length).String(), nil
+}
+
+// cidrSplit allocates consecutive subnets of a prefix, one for each newbits
+// argument before the prefix, as Terraform's cidrsubnets does: every subnet
+// starts at the first address after the previous one aligned to its size.
+func cidrSplit(args ...any) ([]any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("cidrSplit: expected at least 2 arguments, got %d", len(args))
+	}
+	prefix, _ := toString(args[len(args)-1])
+	p, err := parsePrefix("cidrSplit", prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	total := p.Addr().BitLen()
+	base := addrToInt(p.Addr())
+	end := new(big.Int).Add(base, blockSize(total, p.Bits()))
+
+	result := []any{}
+	next := new(big.Int).Set(base)
+	for _, arg := range args[:len(args)-1] {
+		bits, err := toInt(arg)
+		if err != nil {
+			return nil, fmt.Errorf("cidrSplit: invalid new bits: %w", err)
+		}
+		length := p.Bits() + bits
+		if bits < 0 || length > total {
+			return nil, fmt.Errorf("cidrSplit: can't add %d bits to prefix %s", bits, p)
+		}
+
+		size := blockSize(total, length)
+		if rem := new(big.Int).Mod(next, size); rem.Sign() != 0 {
+			next.Add(next, rem.Sub(size, rem))
+		}
+		if new(big.Int).Add(next, size).Cmp(end) > 0 {
+			return nil, fmt.Errorf("cidrSplit: not enough address space left in %s for a subnet with %d new bits", p, bits)
+		}
+
+		addr, _ := intToAddr(next, p.Addr())
+		result = append(result, netip.PrefixFrom(addr, length).String())
+		next.Add(next, size)
+	}
+	return result, nil
+}
+
+// cidrNetmask returns the netmask of an IPv4 prefix in dotted decimal form.
+func cidrNetmask(prefix string) (string, error) {
+	p, err := parsePrefix("cidrNetmask", prefix)
+	if err != nil {
+		return "", err
+	}
+	if !p.Addr().Is4() {
+		return "", fmt.Errorf("cidrNetmask: only IPv4 prefixes have a netmask, got %s", p)
+	}
+	mask := uint32(0xffffffff) << (32 - p.Bits())
+	if p.Bits() == 0 {
+		mask = 0
+	}
+	return netip.AddrFrom4([4]byte{byte(mask >> 24), byte(mask >> 16), byte(mask >> 8), byte(mask)}).String(), nil
+}
+
+// cidrContains reports whether an address or a prefix is inside a prefix.
+func cidrContains(ipOrPrefix, prefix string) (bool, error) {
+	p, err := parsePrefix("cidrContains", prefix)
+	if err != nil {
+		return false, err
+	}
+
+	if strings.Contains(ipOrPrefix, "/") {
+		inner, err := parsePrefix("cidrContains", ipOrPrefix)
+		if err != nil {
+			return false, err
+		}
+		return inner.Bits() >= p.Bits() && p.Contains(inner.Addr()), nil
+	}
+
+	addr, err := parseIP("cidrContains", ipOrPrefix)
+	if err != nil {
+		return false, err
+	}
+	return p.Contains(addr), nil
+}
+
+// ipAdd adds n to an address, n may be negative.
+func ipAdd(n any, ip string) (string, error) {
+	num, err := toInt(n)
+	if err != nil {
+		return "", fmt.Errorf("ipAdd: %w", err)
+	}
+	addr, err := parseIP("ipAdd", ip)
+	if err != nil {
+		return "", err
+	}
+
+	result, ok := intToAddr(new(big.Int).Add(addrToInt(addr), big.NewInt(int64(num))), addr)
+	if !ok {
+		return "", fmt.Errorf("ipAdd: %s + %d is out of the IPv%d address space", addr, num, ipFamily(addr))
+	}
+	return result.WithZone(addr.Zone()).String(), nil
+}
+
+func ipFamily(addr netip.Addr) int {
+	if addr.Is4() {
+		return 4
+	}
+	return 6
+}
+
+// ipVersion returns 4 or 6 for an address or a prefix.
+func ipVersion(ipOrPrefix string) (int, error) {
+	if strings.Contains(ipOrPrefix, "/") {
+		p, err := parsePrefix("ipVersion", ipOrPrefix)
+		if err != nil {
+			return 0, err
+		}
+		return ipFamily(p.Addr()), nil
+	}
+	addr, err := parseIP("ipVersion", ipOrPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return ipFamily(addr), nil
+}
+
+// isPrivateIP reports whether an address is in a private range: 10.0.0.0/8,
+// 172.16.0.0/12 and 192.168.0.0/16 (RFC 1918) or fc00::/7 (RFC 4193).
+func isPrivateIP(ip string) (bool, error) {
+	addr, err := parseIP("isPrivateIP", ip)
+	if err != nil {
+		return false, err
+	}
+	return addr.IsPrivate(), nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCIDRHost(t *testing.T) {
+	tests := []struct {
+		hostnum  any
+		prefix   string
+		expected string
+	}{
+		{16, "10.12.112.0/20", "10.12.112.16"},
+		{268, "10.12.112.0/20", "10.12.113.12"},
+		{-1, "10.12.112.0/20", "10.12.127.255"},
+		{0, "10.12.112.5/20", "10.12.112.0"},
+		{5.0, "192.168.1.0/24", "192.168.1.5"},
+		{"34", "fd00:fd12:3456:7890:00a2::/72", "fd00:fd12:3456:7890::22"},
+		{-2, "2001:db8::/64", "2001:db8::ffff:ffff:ffff:fffe"},
+	}
+
+	for _, tt := range tests {
+		result, err := cidrHost(tt.hostnum, tt.prefix)
+		if err != nil {
+			t.Fatalf("cidrHost(%v, %q): unexpected error: %v", tt.hostnum, tt.prefix, err)
+		}
+		if result != tt.expected {
+			t.Errorf("cidrHost(%v, %q): expected %s, got %s", tt.hostnum, tt.prefix, tt.expected, result)
+		}
+	}
+
+	for _, tt := range []struct {
+		hostnum any
+		prefix  string
+	}{
+		{256, "10.0.0.0/24"},
+		{-257, "10.0.0.0/24"},
+		{1, "10.0.0.0"},
+		{1, "10.0.0.0/33"},
+		{"x", "10.0.0.0/24"},
+	} {
+		if _, err := cidrHost(tt.hostnum, tt.prefix); err == nil {
+			t.Errorf("cidrHost(%v, %q): expected error", tt.hostnum, tt.prefix)
+		}
+	}
+}
+
+func TestCIDRSubnet(t *testing.T) {
+	tests := []struct {
+		newbits, netnum any
+		prefix          string
+		expected        string
+	}{
+		{4, 2, "172.16.0.0/12", "172.18.0.0/16"},
+		{8, 2, "172.16.0.0/12", "172.16.32.0/20"},
+		{4, 15, "10.1.2.0/24", "10.1.2.240/28"},
+		{16, 162, "fd00:fd12:3456:7890::/56", "fd00:fd12:3456:7800:a200::/72"},
+		{0, 0, "10.0.0.0/8", "10.0.0.0/8"},
+	}
+
+	for _, tt := range tests {
+		result, err := cidrSubnet(tt.newbits, tt.netnum, tt.prefix)
+		if err != nil {
+			t.Fatalf("cidrSubnet(%v, %v, %q): unexpected error: %v", tt.newbits, tt.netnum, tt.prefix, err)
+		}
+		if result != tt.expected {
+			t.Errorf("cidrSubnet(%v, %v, %q): expected %s, got %s", tt.newbits, tt.netnum, tt.prefix, tt.expected, result)
+		}
+	}
+
+	for _, tt := range []struct {
+		newbits, netnum any
+		prefix          string
+	}{
+		{4, 16, "10.1.2.0/24"},
+		{4, -1, "10.1.2.0/24"},
+		{9, 0, "10.1.2.0/24"},
+		{-1, 0, "10.1.2.0/24"},
+		{4, 0, "invalid"},
+	} {
+		if _, err := cidrSubnet(tt.newbits, tt.netnum, tt.prefix); err == nil {
+			t.Errorf("cidrSubnet(%v, %v, %q): expected error", tt.newbits, tt.netnum, tt.prefix)
+		}
+	}
+}
+
+func TestCIDRSplit(t *testing.T) {
+	tests := []struct {
+		args     []any
+		expected []any
+	}{
+		{[]any{4, 4, 8, 4, "10.1.0.0/16"}, []any{"10.1.0.0/20", "10.1.16.0/20", "10.1.32.0/24", "10.1.48.0/20"}},
+		{[]any{8, 4, "10.0.0.0/16"}, []any{"10.0.0.0/24", "10.0.16.0/20"}},
+		{[]any{1, 1, "10.0.0.0/8"}, []any{"10.0.0.0/9", "10.128.0.0/9"}},
+		{[]any{16, 16, 16, 32, "fd00:fd12:3456:7890::/56"}, []any{
+			"fd00:fd12:3456:7800::/72", "fd00:fd12:3456:7800:100::/72", "fd00:fd12:3456:7800:200::/72", "fd00:fd12:3456:7800:300::/88",
+		}},
+	}
+
+	for _, tt := range tests {
+		result, err := cidrSplit(tt.args...)
+		if err != nil {
+			t.Fatalf("cidrSplit(%v): unexpected error: %v", tt.args, err)
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("cidrSplit(%v): expected %v, got %v", tt.args, tt.expected, result)
+		}
+	}
+
+	for _, args := range [][]any{
+		{"10.0.0.0/8"},
+		{1, 1, 1, "10.0.0.0/8"},
+		{25, "10.0.0.0/8"},
+		{"x", "10.0.0.0/8"},
+		{4, "10.0.0.0"},
+	} {
+		if _, err := cidrSplit(args...); err == nil {
+			t.Errorf("cidrSplit(%v): expected error", args)
+		}
+	}
+}
+
+func TestCIDRNetmask(t *testing.T) {
+	tests := map[string]string{
+		"172.16.0.0/12": "255.240.0.0",
+		"10.0.0.0/24":   "255.255.255.0",
+		"10.0.0.1/32":   "255.255.255.255",
+		"0.0.0.0/0":     "0.0.0.0",
+	}
+	for prefix, expected := range tests {
+		result, err := cidrNetmask(prefix)
+		if err != nil {
+			t.Fatalf("cidrNetmask(%q): unexpected error: %v", prefix, err)
+		}
+		if result != expected {
+			t.Errorf("cidrNetmask(%q): expected %s, got %s", prefix, expected, result)
+		}
+	}
+
+	if _, err := cidrNetmask("2001:db8::/32"); err == nil {
+		t.Errorf("expected error for IPv6 prefix")
+	}
+}
+
+func TestCIDRContains(t *testing.T) {
+	tests := []struct {
+		ipOrPrefix, prefix string
+		expected           bool
+	}{
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"11.1.2.3", "10.0.0.0/8", false},
+		{"10.1.0.0/16", "10.0.0.0/8", true},
+		{"10.0.0.0/7", "10.0.0.0/8", false},
+		{"2001:db8::1", "2001:db8::/32", true},
+		{"2001:db9::1", "2001:db8::/32", false},
+		{"10.1.2.3", "2001:db8::/32", false},
+	}
+	for _, tt := range tests {
+		result, err := cidrContains(tt.ipOrPrefix, tt.prefix)
+		if err != nil {
+			t.Fatalf("cidrContains(%q, %q): unexpected error: %v", tt.ipOrPrefix, tt.prefix, err)
+		}
+		if result != tt.expected {
+			t.Errorf("cidrContains(%q, %q): expected %v, got %v", tt.ipOrPrefix, tt.prefix, tt.expected, result)
+		}
+	}
+
+	if _, err := cidrContains("10.1.2", "10.0.0.0/8"); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+	if _, err := cidrContains("10.1.2.3", "10.0.0.0"); err == nil {
+		t.Errorf("expected error for invalid prefix")
+	}
+}
+
+func TestIPHelpers(t *testing.T) {
+	addTests := []struct {
+		n        any
+		ip       string
+		expected string
+	}{
+		{1, "10.0.0.255", "10.0.1.0"},
+		{-1, "10.0.1.0", "10.0.0.255"},
+		{256.0, "2001:db8::ff", "2001:db8::1ff"},
+		{1, "fe80::1%eth0", "fe80::2%eth0"},
+	}
+	for _, tt := range addTests {
+		result, err := ipAdd(tt.n, tt.ip)
+		if err != nil {
+			t.Fatalf("ipAdd(%v, %q): unexpected error: %v", tt.n, tt.ip, err)
+		}
+		if result != tt.expected {
+			t.Errorf("ipAdd(%v, %q): expected %s, got %s", tt.n, tt.ip, tt.expected, result)
+		}
+	}
+	if _, err := ipAdd(1, "255.255.255.255"); err == nil {
+		t.Errorf("expected overflow error")
+	}
+	if _, err := ipAdd(-1, "::"); err == nil {
+		t.Errorf("expected underflow error")
+	}
+
+	for input, expected := range map[string]int{"10.0.0.1": 4, "::1": 6, "10.0.0.0/8": 4, "2001:db8::/32": 6} {
+		if result, err := ipVersion(input); err != nil || result != expected {
+			t.Errorf("ipVersion(%q): expected %d, got %d, %v", input, expected, result, err)
+		}
+	}
+	if _, err := ipVersion("example.com"); err == nil {
+		t.Errorf("expected error for host name")
+	}
+
+	for input, expected := range map[string]bool{
+		"10.1.2.3": true, "172.16.0.1": true, "172.32.0.1": false, "192.168.0.1": true,
+		"8.8.8.8": false, "127.0.0.1": false, "fd00::1": true, "2001:db8::1": false,
+	} {
+		if result, err := isPrivateIP(input); err != nil || result != expected {
+			t.Errorf("isPrivateIP(%q): expected %v, got %v, %v", input, expected, result, err)
+		}
+	}
+	if _, err := isPrivateIP("10.0.0.0/8"); err == nil {
+		t.Errorf("expected error for prefix")
+	}
+}
+
+func TestCIDRTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"vpc": "10.0.0.0/16", "ip": "10.0.3.7"}
+	tpl := `{{ range $i, $s := .vpc | cidrSplit 8 8 }}{{ $s }} gw={{ $s | cidrHost 1 }} {{ end }}{{ .vpc | cidrContains .ip }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "10.0.0.0/24 gw=10.0.0.1 10.0.1.0/24 gw=10.0.1.1 true"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// toList converts any slice or array to []any, so helpers work both on the
+// decoded JSON data and on the results of other helpers like split.
+func toList(v any) ([]any, error) {
+	switch val := v.(type) {
+	case []any:
+		return val, nil
+	case nil:
+		return []any{}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", v)
+	}
+
+	result := make([]any, rv.Len())
+	for i := range result {
+		result[i] = rv.Index(i).Interface()
+	}
+	return result, nil
+}
+
+// keys returns the keys of a map in sorted order, so iterating over them
+// gives the same output on every run.
+func keys(m map[string]any) []any {
+	result := make([]any, 0, len(m))
+	for _, k := range sortedMapKeys(m) {
+		result = append(result, k)
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a map in natural order, where digit runs are
+// compared by their numeric value ("item2" comes before "item10").
+func sortedKeys(m map[string]any) []any {
+	list := sortedMapKeys(m)
+	sort.SliceStable(list, func(i, j int) bool {
+		return naturalCompare(list[i], list[j]) < 0
+	})
+	result := make([]any, len(list))
+	for i, k := range list {
+		result[i] = k
+	}
+	return result
+}
+
+// values returns the values of a map ordered by their keys.
+func values(m map[string]any) []any {
+	result := make([]any, 0, len(m))
+	for _, k := range sortedMapKeys(m) {
+		result = append(result, m[k])
+	}
+	return result
+}
+
+// entries returns the key/value pairs of a map ordered by their keys. Every
+// entry is a map with a "key" and a "value" field.
+func entries(m map[string]any) []any {
+	result := make([]any, 0, len(m))
+	for _, k := range sortedMapKeys(m) {
+		result = append(result, map[string]any{"key": k, "value": m[k]})
+	}
+	return result
+}
+
+// sortBy returns a copy of a list of maps ordered by the value at the field
+// path. The last argument is the list, the arguments between the path and
+// the list are options: "string", "numeric" or "natural" to choose the
+// comparison (by default numbers are compared as numbers and everything else
+// as strings) and "reverse" to sort in descending order. Items without the
+// field are moved to the end.
+func sortBy(path string, args ...any) ([]any, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("sortBy: missing list argument")
+	}
+
+	list, err := toList(args[len(args)-1])
+	if err != nil {
+		return nil, fmt.Errorf("sortBy: %w", err)
+	}
+
+	mode := "auto"
+	descending := false
+	for _, arg := range args[:len(args)-1] {
+		switch arg {
+		case "string", "numeric", "natural":
+			mode = arg.(string)
+		case "reverse", "desc":
+			descending = true
+		case "asc":
+			descending = false
+		default:
+			return nil, fmt.Errorf("sortBy: unknown option '%v'", arg)
+		}
+	}
+
+	type item struct {
+		value   any
+		key     any
+		missing bool
+	}
+
+	items := make([]item, len(list))
+	for i, v := range list {
+		key, ok := lookupPath(v, path)
+		items[i] = item{value: v, key: key, missing: !ok || key == nil}
+		if mode == "numeric" && !items[i].missing {
+			f, err := toFloat(key)
+			if err != nil {
+				return nil, fmt.Errorf("sortBy: item %d: %w", i, err)
+			}
+			items[i].key = f
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.missing || b.missing {
+			return !a.missing && b.missing
+		}
+		c := compareValues(a.key, b.key, mode)
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	result := make([]any, len(items))
+	for i, it := range items {
+		result[i] = it.value
+	}
+	return result, nil
+}
+
+// where returns the items of a list of maps whose value at the field path
+// matches. It is called either with a value to compare for equality
+// (where "status" "active" .items) or with an operator and a value
+// (where "age" "gt" 30 .items). Supported operators are eq, ne, gt, ge, lt,
+// le, in, notIn and contains.
+func where(path string, args ...any) ([]any, error) {
+	var op string
+	var value, listArg any
+
+	switch len(args) {
+	case 2:
+		op, value, listArg = "eq", args[0], args[1]
+	case 3:
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("where: operator must be a string, got %T", args[0])
+		}
+		op, value, listArg = s, args[1], args[2]
+	default:
+		return nil, fmt.Errorf("where: expected 3 or 4 arguments, got %d", len(args)+1)
+	}
+
+	list, err := toList(listArg)
+	if err != nil {
+		return nil, fmt.Errorf("where: %w", err)
+	}
+
+	result := []any{}
+	for _, item := range list {
+		field, _ := lookupPath(item, path)
+		ok, err := matchValue(field, op, value)
+		if err != nil {
+			return nil, fmt.Errorf("where: %w", err)
+		}
+		if ok {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func matchValue(field any, op string, value any) (bool, error) {
+	switch op {
+	case "eq", "==":
+		return valuesEqual(field, value), nil
+	case "ne", "!=":
+		return !valuesEqual(field, value), nil
+	case "gt", ">":
+		return field != nil && compareValues(field, value, "auto") > 0, nil
+	case "ge", "gte", ">=":
+		return field != nil && compareValues(field, value, "auto") >= 0, nil
+	case "lt", "<":
+		return field != nil && compareValues(field, value, "auto") < 0, nil
+	case "le", "lte", "<=":
+		return field != nil && compareValues(field, value, "auto") <= 0, nil
+	case "in", "notIn":
+		list, err := toList(value)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		return (indexOf(field, list) >= 0) == (op == "in"), nil
+	case "contains":
+		switch f := field.(type) {
+		case string:
+			s, _ := toString(value)
+			return strings.Contains(f, s), nil
+		case map[string]any:
+			s, _ := toString(value)
+			_, ok := f[s]
+			return ok, nil
+		case []any:
+			return indexOf(value, f) >= 0, nil
+		default:
+			return false, nil
+		}
+	default:
+		return false, fmt.Errorf("unknown operator '%s'", op)
+	}
+}
+
+func valuesEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch a.(type) {
+	case map[string]any, []any:
+		return reflect.DeepEqual(a, b)
+	}
+	return compareValues(a, b, "auto") == 0
+}
+
+// pluck returns the values at the field path of every item in the list.
+// Items without the field are skipped.
+func pluck(path string, v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("pluck: %w", err)
+	}
+
+	result := []any{}
+	for _, item := range list {
+		if value, ok := lookupPath(item, path); ok {
+			result = append(result, value)
+		}
+	}
+	return result, nil
+}
+
+// groupBy groups the items of a list by the value at the field path. Items
+// without the field are grouped under the empty string.
+func groupBy(path string, v any) (map[string]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("groupBy: %w", err)
+	}
+
+	result := map[string]any{}
+	for _, item := range list {
+		value, _ := lookupPath(item, path)
+		key, _ := toString(value)
+		group, _ := result[key].([]any)
+		result[key] = append(group, item)
+	}
+	return result, nil
+}
+
+// uniq returns the list without duplicate items, keeping the first occurrence.
+func uniq(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("uniq: %w", err)
+	}
+
+	result := []any{}
+	for _, item := range list {
+		if indexOf(item, result) < 0 {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// flatten returns the items of nested lists as a single list.
+func flatten(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("flatten: %w", err)
+	}
+
+	result := []any{}
+	for _, item := range list {
+		if nested, err := toList(item); err == nil && item != nil {
+			flat, err := flatten(nested)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, flat...)
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// chunk splits a list into lists of at most size items.
+func chunk(size int, v any) ([]any, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk: size must be positive, got %d", size)
+	}
+
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("chunk: %w", err)
+	}
+
+	result := []any{}
+	for start := 0; start < len(list); start += size {
+		end := min(start+size, len(list))
+		result = append(result, append([]any{}, list[start:end]...))
+	}
+	return result, nil
+}
+
+// reverse returns a reversed copy of the list.
+func reverse(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("reverse: %w", err)
+	}
+
+	result := make([]any, len(list))
+	for i, item := range list {
+		result[len(list)-1-i] = item
+	}
+	return result, nil
+}
+
+// compact returns the list without the items considered empty by the empty
+// helper.
+func compact(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("compact: %w", err)
+	}
+
+	result := []any{}
+	for _, item := range list {
+		if !isEmpty(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// indexOf returns the index of the first item equal to needle, or -1.
+func indexOf(needle any, v any) int {
+	list, err := toList(v)
+	if err != nil {
+		return -1
+	}
+
+	for i, item := range list {
+		if valuesEqual(item, needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// has reports whether the list contains the needle.
+func has(needle any, v any) bool {
+	return indexOf(needle, v) >= 0
+}
+
+// appendItem returns a copy of the list with the item added to its end.
+func appendItem(item any, v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("append: %w", err)
+	}
+	return append(append(make([]any, 0, len(list)+1), list...), item), nil
+}
+
+// prependItem returns a copy of the list with the item added to its start.
+func prependItem(item any, v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("prepend: %w", err)
+	}
+	return append([]any{item}, list...), nil
+}
+
+// concat joins the given lists into a new list.
+func concat(lists ...any) ([]any, error) {
+	result := []any{}
+	for i, v := range lists {
+		list, err := toList(v)
+		if err != nil {
+			return nil, fmt.Errorf("concat: argument %d: %w", i, err)
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+// dict builds a map from key and value pairs.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: expected key and value pairs, got %d arguments", len(pairs))
+	}
+
+	result := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, _ := toString(pairs[i])
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
+
+// list builds a list from its arguments.
+func list(items ...any) []any {
+	return append([]any{}, items...)
+}
+
+func compareValues(a, b any, mode string) int {
+	switch mode {
+	case "natural":
+		as, _ := toString(a)
+		bs, _ := toString(b)
+		return naturalCompare(as, bs)
+	case "string":
+		as, _ := toString(a)
+		bs, _ := toString(b)
+		return strings.Compare(as, bs)
+	}
+
+	if cmp, ok := compareNumbers(a, b); ok {
+		return cmp
+	}
+
+	af, aErr := toFloat(a)
+	bf, bErr := toFloat(b)
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+	if aErr == nil && bErr == nil && !aIsString && !bIsString {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	as, _ := toString(a)
+	bs, _ := toString(b)
+	return strings.Compare(as, bs)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// naturalCompare compares two strings treating runs of digits as numbers.
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si := i
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			sj := j
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+
+			na := strings.TrimLeft(a[si:i], "0")
+			nb := strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				if len(na) < len(nb) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapIterationHelpers(t *testing.T) {
+	m := map[string]any{"item10": 3, "item2": 2, "b": 1, "a": 0}
+
+	tests := []struct {
+		name     string
+		result   []any
+		expected []any
+	}{
+		{"keys", keys(m), []any{"a", "b", "item10", "item2"}},
+		{"sortedKeys", sortedKeys(m), []any{"a", "b", "item2", "item10"}},
+		{"values", values(m), []any{0, 1, 3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !equalSlices(tt.result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, tt.result)
+			}
+		})
+	}
+
+	t.Run("entries", func(t *testing.T) {
+		result := entries(map[string]any{"b": 2, "a": 1})
+		if len(result) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(result))
+		}
+		first := result[0].(map[string]any)
+		if first["key"] != "a" || first["value"] != 1 {
+			t.Errorf("unexpected first entry: %v", first)
+		}
+	})
+}
+
+func TestSortBy(t *testing.T) {
+	items := []any{
+		map[string]any{"name": "file10", "size": 2.0, "meta": map[string]any{"rank": "b"}},
+		map[string]any{"name": "file2", "size": 10.0, "meta": map[string]any{"rank": "c"}},
+		map[string]any{"name": "file1", "size": "3"},
+		map[string]any{"name": "file3", "size": 1.0, "meta": map[string]any{"rank": "a"}},
+	}
+
+	names := func(list []any) string {
+		var result []string
+		for _, v := range list {
+			result = append(result, v.(map[string]any)["name"].(string))
+		}
+		return strings.Join(result, ",")
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     []any
+		expected string
+		hasError bool
+	}{
+		{"string", "name", nil, "file1,file10,file2,file3", false},
+		{"natural", "name", []any{"natural"}, "file1,file2,file3,file10", false},
+		{"natural reverse", "name", []any{"natural", "reverse"}, "file10,file3,file2,file1", false},
+		{"numeric", "size", []any{"numeric"}, "file3,file10,file1,file2", false},
+		{"nested path, missing last", "meta.rank", nil, "file3,file10,file2,file1", false},
+		{"missing last when reversed", "meta.rank", []any{"desc"}, "file2,file10,file3,file1", false},
+		{"unknown option", "name", []any{"random"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(append([]any{}, tt.opts...), items)
+			result, err := sortBy(tt.path, args...)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := names(result); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("does not modify input", func(t *testing.T) {
+		if _, err := sortBy("name", "natural", items); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := names(items); got != "file10,file2,file1,file3" {
+			t.Errorf("input list was modified: %s", got)
+		}
+	})
+
+	t.Run("template", func(t *testing.T) {
+		var buf strings.Builder
+		tpl := `{{ range sortBy "age" "reverse" .people }}{{ .name }} {{ end }}`
+		data := map[string]any{"people": []any{
+			map[string]any{"name": "a", "age": 30.0},
+			map[string]any{"name": "b", "age": 40.0},
+		}}
+		if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "b a " {
+			t.Errorf("expected %q, got %q", "b a ", buf.String())
+		}
+	})
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"a2", "a10", -1},
+		{"a10", "a2", 1},
+		{"a02", "a2", -1},
+		{"abc", "abc", 0},
+		{"1.10", "1.9", 1},
+		{"x", "x1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.expected {
+			t.Errorf("naturalCompare(%q, %q) expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestWhere(t *testing.T) {
+	items := []any{
+		map[string]any{"name": "a", "status": "active", "age": 20.0, "tags": []any{"x", "y"}},
+		map[string]any{"name": "b", "status": "inactive", "age": 35.0, "tags": []any{"y"}},
+		map[string]any{"name": "c", "status": "active", "age": 50.0},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		args     []any
+		expected []any
+		hasError bool
+	}{
+		{"equality", "status", []any{"active"}, []any{"a", "c"}, false},
+		{"ne", "status", []any{"ne", "active"}, []any{"b"}, false},
+		{"gt", "age", []any{"gt", 30}, []any{"b", "c"}, false},
+		{"le", "age", []any{"le", 35}, []any{"a", "b"}, false},
+		{"in", "name", []any{"in", []any{"a", "b"}}, []any{"a", "b"}, false},
+		{"in strings", "name", []any{"in", []string{"c"}}, []any{"c"}, false},
+		{"notIn", "name", []any{"notIn", []any{"a", "b"}}, []any{"c"}, false},
+		{"contains list", "tags", []any{"contains", "x"}, []any{"a"}, false},
+		{"contains string", "status", []any{"contains", "in"}, []any{"b"}, false},
+		{"unknown operator", "status", []any{"like", "a"}, nil, true},
+		{"missing list", "status", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := where(tt.path, append(append([]any{}, tt.args...), items)...)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			names, _ := pluck("name", result)
+			if !equalSlices(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestListHelpers(t *testing.T) {
+	items := []any{
+		map[string]any{"name": "a", "group": "x"},
+		map[string]any{"name": "b", "group": "y"},
+		map[string]any{"name": "c", "group": "x"},
+		map[string]any{"group": "y"},
+	}
+
+	tests := []struct {
+		name     string
+		fn       func() (any, error)
+		expected any
+	}{
+		{"pluck", func() (any, error) { return pluck("name", items) }, []any{"a", "b", "c"}},
+		{"groupBy", func() (any, error) {
+			groups, err := groupBy("group", items)
+			if err != nil {
+				return nil, err
+			}
+			return pluck("name", groups["x"])
+		}, []any{"a", "c"}},
+		{"uniq", func() (any, error) { return uniq([]any{1, "a", 1, 2, "a", 1.0}) }, []any{1, "a", 2}},
+		{"uniq strings", func() (any, error) { return uniq([]string{"a", "b", "a"}) }, []any{"a", "b"}},
+		{"flatten", func() (any, error) { return flatten([]any{1, []any{2, []any{3, "ab"}}, []string{"c"}}) }, []any{1, 2, 3, "ab", "c"}},
+		{"chunk", func() (any, error) { return chunk(2, []any{1, 2, 3, 4, 5}) }, []any{[]any{1, 2}, []any{3, 4}, []any{5}}},
+		{"reverse", func() (any, error) { return reverse([]any{1, 2, 3}) }, []any{3, 2, 1}},
+		{"compact", func() (any, error) { return compact([]any{1, "", nil, []any{}, "a", 0}) }, []any{1, "a", 0}},
+		{"append", func() (any, error) { return appendItem(3, []any{1, 2}) }, []any{1, 2, 3}},
+		{"prepend", func() (any, error) { return prependItem(0, []any{1, 2}) }, []any{0, 1, 2}},
+		{"concat", func() (any, error) { return concat([]any{1}, []string{"a"}, []any{}) }, []any{1, "a"}},
+		{"list", func() (any, error) { return list(1, "a"), nil }, []any{1, "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalValues(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("indexOf and has", func(t *testing.T) {
+		list := []any{"a", 2.0, "c"}
+		if got := indexOf(2, list); got != 1 {
+			t.Errorf("expected 1, got %d", got)
+		}
+		if got := indexOf("x", list); got != -1 {
+			t.Errorf("expected -1, got %d", got)
+		}
+		if !has("c", list) || has("d", list) {
+			t.Errorf("has returned wrong result")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := chunk(0, []any{1}); err == nil {
+			t.Errorf("expected error for chunk size 0")
+		}
+		if _, err := dict("a"); err == nil {
+			t.Errorf("expected error for odd number of dict arguments")
+		}
+		if _, err := reverse("abc"); err == nil {
+			t.Errorf("expected error for non list value")
+		}
+	})
+
+	t.Run("append does not modify input", func(t *testing.T) {
+		input := make([]any, 1, 10)
+		input[0] = 1
+		a, _ := appendItem(2, input)
+		b, _ := appendItem(3, input)
+		if a[1] != 2 || b[1] != 3 {
+			t.Errorf("append results share memory: %v %v", a, b)
+		}
+	})
+}
+
+func TestCollectionTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "where and pluck",
+			template: `{{ where "status" "active" .items | pluck "name" | toStrings | join "," }}`,
+			expected: "a,c",
+		},
+		{
+			name:     "dict and list",
+			template: `{{ $d := dict "name" "x" "tags" (list 1 2) }}{{ $d.name }} {{ len $d.tags }}`,
+			expected: "x 2",
+		},
+		{
+			name:     "groupBy",
+			template: `{{ range $k, $v := groupBy "status" .items }}{{ $k }}={{ len $v }} {{ end }}`,
+			expected: "active=2 inactive=1 ",
+		},
+		{
+			name:     "uniq on split",
+			template: `{{ split "," "a,b,a" | uniq | toStrings | join "," }}`,
+			expected: "a,b",
+		},
+	}
+
+	data := map[string]any{"items": []any{
+		map[string]any{"name": "a", "status": "active"},
+		map[string]any{"name": "b", "status": "inactive"},
+		map[string]any{"name": "c", "status": "active"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := executeTemplate(&buf, tt.template, data, renderConfig{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAnyLayouts lists the layouts parseAny tries, in order. Dates with
+// slashes are always read as US month/day, so 03/04/2024 is March 4, and
+// dates with dots or dashes as day/month. Times without a zone are in UTC.
+var parseAnyLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006-01",
+	"20060102",
+	time.RFC1123,
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC850,
+	time.RFC822,
+	time.RFC822Z,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2 15:04:05",
+	"2006.1.2 15:04",
+	"2006.1.2.",
+	"2006.1.2",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+	"1/2/2006",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2.1.2006",
+	"2-1-2006 15:04:05",
+	"2-1-2006 15:04",
+	"2-1-2006",
+	"January 2, 2006 15:04:05",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"Mon Jan 2 2006",
+	"2 January 2006 15:04:05",
+	"2 January 2006 15:04",
+	"2 January 2006",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"Monday, January 2, 2006",
+	"Mon, 2 Jan 2006",
+}
+
+// The Unix timestamp thresholds separate seconds, milliseconds,
+// microseconds and nanoseconds: 1e11 seconds is in the year 5138, 1e11
+// milliseconds in 1973, and likewise for the smaller units.
+const (
+	epochMillisThreshold = 1e11
+	epochMicrosThreshold = 1e14
+	epochNanosThreshold  = 1e17
+)
+
+// parseAny parses a date without a layout. It recognizes RFC 3339 and other
+// ISO 8601 forms, RFC 1123 and the other formats of the time package, Unix
+// timestamps in seconds, milliseconds, microseconds or nanoseconds, and
+// common US and European formats. Strings are matched against the layouts
+// before they are read as timestamps, so "20240101" is a date.
+func parseAny(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case int:
+		return epochToTime(int64(t)), nil
+	case int64:
+		return epochToTime(t), nil
+	case float64:
+		return floatEpochToTime(t)
+	case json.Number:
+		return parseAny(string(t))
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range parseAnyLayouts {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				return parsed, nil
+			}
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return epochToTime(n), nil
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "eEnN") {
+			return floatEpochToTime(n)
+		}
+		return time.Time{}, fmt.Errorf("unrecognized date format '%s'", t)
+	default:
+		return time.Time{}, fmt.Errorf("cannot convert %T to time", v)
+	}
+}
+
+// epochToTime converts an integer Unix timestamp with the integer functions
+// of the time package, so milliseconds like 1700000000123 keep their digits.
+func epochToTime(n int64) time.Time {
+	switch {
+	case n > -epochMillisThreshold && n < epochMillisThreshold:
+		return time.Unix(n, 0).UTC()
+	case n > -epochMicrosThreshold && n < epochMicrosThreshold:
+		return time.UnixMilli(n).UTC()
+	case n > -epochNanosThreshold && n < epochNanosThreshold:
+		return time.UnixMicro(n).UTC()
+	default:
+		return time.Unix(0, n).UTC()
+	}
+}
+
+func floatEpochToTime(n float64) (time.Time, error) {
+	if n == math.Trunc(n) && math.Abs(n) < math.MaxInt64 {
+		return epochToTime(int64(n)), nil
+	}
+	switch a := math.Abs(n); {
+	case a >= epochNanosThreshold:
+		n /= 1e9
+	case a >= epochMicrosThreshold:
+		n /= 1e6
+	case a >= epochMillisThreshold:
+		n /= 1e3
+	}
+	return unixToTime(n)
+}
+
+// namedDateFormats are the format names formatDate accepts besides Go
+// layouts and strftime formats.
+var namedDateFormats = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"iso8601":     "2006-01-02T15:04:05Z0700",
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"rfc850":      time.RFC850,
+	"ansic":       time.ANSIC,
+	"unixdate":    time.UnixDate,
+	"rubydate":    time.RubyDate,
+	"kitchen":     time.Kitchen,
+	"stamp":       time.Stamp,
+	"datetime":    time.DateTime,
+	"date":        time.DateOnly,
+	"time":        time.TimeOnly,
+}
+
+// formatDate formats a time with a named format ("rfc3339", "kitchen",
+// "iso8601", ...), a strftime format ("%Y-%m-%d") or a Go layout
+// ("2006-01-02").
+func formatDate(format string, v any) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("formatDate: %w", err)
+	}
+	if layout, ok := namedDateFormats[strings.ToLower(format)]; ok {
+		return t.Format(layout), nil
+	}
+	if isStrftime(format) {
+		return strftime(format, t)
+	}
+	return t.Format(format), nil
+}
+
+// strftimeLayouts maps the strftime conversions to Go layouts.
+var strftimeLayouts = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'H': "15",
+	'I': "03", 'M': "04", 'S': "05", 'p': "PM", 'b': "Jan", 'h': "Jan",
+	'B': "January", 'a': "Mon", 'A': "Monday", 'Z': "MST", 'z': "-0700",
+	'F': "2006-01-02", 'T': "15:04:05", 'R': "15:04", 'D': "01/02/06",
+	'c': "Mon Jan _2 15:04:05 2006", 'x': "01/02/06", 'X': "15:04:05",
+	'r': "03:04:05 PM",
+}
+
+// strftimeConversions are the conversions strftime supports besides the
+// ones in strftimeLayouts.
+const strftimeConversions = "jsfLuwVGklnt"
+
+// isStrftime reports whether a format has a strftime conversion like "%Y".
+// A percent sign followed by anything else, like the one of a Go layout
+// "15:04 (% done)", is not one.
+func isStrftime(format string) bool {
+	for i := 0; i+1 < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		conv := format[i+1]
+		if _, ok := strftimeLayouts[conv]; ok || strings.IndexByte(strftimeConversions, conv) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// strftime formats a time with C strftime conversions like "%Y-%m-%d".
+// Besides the conversions mapped to Go layouts it supports %j (day of the
+// year), %s (Unix timestamp), %f (microseconds), %L (milliseconds), %u and %w
+// (day of the week, from 1 and 0), %V and %G (ISO week and year), %k and %l
+// (space padded hours), %n, %t and %%.
+func strftime(format string, t time.Time) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return "", fmt.Errorf("formatDate: format '%s' ends with %%", format)
+		}
+
+		conv := format[i]
+		if layout, ok := strftimeLayouts[conv]; ok {
+			b.WriteString(t.Format(layout))
+			continue
+		}
+
+		switch conv {
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'f':
+			fmt.Fprintf(&b, "%06d", t.Nanosecond()/1000)
+		case 'L':
+			fmt.Fprintf(&b, "%03d", t.Nanosecond()/1000000)
+		case 'u':
+			b.WriteString(strconv.Itoa((int(t.Weekday())+6)%7 + 1))
+		case 'w':
+			b.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", week)
+		case 'G':
+			year, _ := t.ISOWeek()
+			b.WriteString(strconv.Itoa(year))
+		case 'k':
+			fmt.Fprintf(&b, "%2d", t.Hour())
+		case 'l':
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			fmt.Fprintf(&b, "%2d", hour)
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", fmt.Errorf("formatDate: unknown conversion %%%c in '%s'", conv, format)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAny(t *testing.T) {
+	utcTime := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+	dayOnly := utcTime(2024, 3, 15, 0, 0, 0)
+	withTime := utcTime(2024, 3, 15, 10, 30, 0)
+
+	tests := []struct {
+		input    any
+		expected time.Time
+	}{
+		{"2024-03-15T10:30:00Z", withTime},
+		{"2024-03-15T11:30:00+01:00", withTime},
+		{"2024-03-15T10:30:00.000Z", withTime},
+		{"2024-03-15T10:30:00", withTime},
+		{"2024-03-15T10:30", withTime},
+		{"2024-03-15 10:30:00", withTime},
+		{"2024-03-15 10:30", withTime},
+		{"2024-03-15", dayOnly},
+		{"2024-03", utcTime(2024, 3, 1, 0, 0, 0)},
+		{"Fri, 15 Mar 2024 10:30:00 GMT", withTime},
+		{"Fri, 15 Mar 2024 11:30:00 +0100", withTime},
+		{"Friday, 15-Mar-24 10:30:00 UTC", withTime},
+		{"Fri Mar 15 10:30:00 2024", withTime},
+		{"Fri Mar 15 10:30:00 UTC 2024", withTime},
+		{"1710498600", withTime},
+		{"1710498600000", withTime},
+		{1710498600, withTime},
+		{int64(1710498600000), withTime},
+		{1710498600.0, withTime},
+		{"03/15/2024", dayOnly},
+		{"3/15/2024 10:30", withTime},
+		{"3/15/2024 10:30 AM", withTime},
+		{"03/04/2024", utcTime(2024, 3, 4, 0, 0, 0)},
+		{"20240315", dayOnly},
+		{"1710498600123", withTime.Add(123 * time.Millisecond)},
+		{json.Number("1700000000123"), time.UnixMilli(1700000000123).UTC()},
+		{int64(1710498600123456), withTime.Add(123456 * time.Microsecond)},
+		{"1710498600123456789", withTime.Add(123456789)},
+		{"15.03.2024", dayOnly},
+		{"15.3.2024 10:30", withTime},
+		{"15-03-2024", dayOnly},
+		{"2024/03/15", dayOnly},
+		{"2024.03.15.", dayOnly},
+		{"March 15, 2024", dayOnly},
+		{"Mar 15, 2024 10:30:00", withTime},
+		{"15 March 2024", dayOnly},
+		{"15 Mar 2024 10:30", withTime},
+		{"  2024-03-15  ", dayOnly},
+		{withTime, withTime},
+	}
+
+	for _, tt := range tests {
+		result, err := parseAny(tt.input)
+		if err != nil {
+			t.Errorf("parseAny(%v): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !result.Equal(tt.expected) {
+			t.Errorf("parseAny(%v): expected %v, got %v", tt.input, tt.expected, result)
+		}
+	}
+
+	for _, input := range []any{"", "yesterday", "2024-13-45", "32/13/2024", "15/03/2024", "1e9", nil, []any{}} {
+		if _, err := parseAny(input); err == nil {
+			t.Errorf("parseAny(%v): expected error", input)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	base := time.Date(2024, 3, 5, 14, 7, 9, 123456789, time.UTC)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"2006-01-02 15:04:05", "2024-03-05 14:07:09"},
+		{"rfc3339", "2024-03-05T14:07:09Z"},
+		{"RFC3339", "2024-03-05T14:07:09Z"},
+		{"iso8601", "2024-03-05T14:07:09Z"},
+		{"kitchen", "2:07PM"},
+		{"rfc1123", "Tue, 05 Mar 2024 14:07:09 UTC"},
+		{"date", "2024-03-05"},
+		{"datetime", "2024-03-05 14:07:09"},
+		{"%Y-%m-%d", "2024-03-05"},
+		{"%d/%m/%y %H:%M:%S", "05/03/24 14:07:09"},
+		{"%e %B %Y, %A", " 5 March 2024, Tuesday"},
+		{"%b %a %I:%M %p", "Mar Tue 02:07 PM"},
+		{"%F %T %Z %z", "2024-03-05 14:07:09 UTC +0000"},
+		{"%j %u %w %V %G", "065 2 2 10 2024"},
+		{"%s.%L %f", "1709647629.123 123456"},
+		{"%k|%l|%R", "14| 2|14:07"},
+		{"100%% at %H", "100% at 14"},
+		{"Year: 2006 %Y", "Year: 2006 2024"},
+		{"2006-01-02 %", "2024-03-05 %"},
+		{"15:04 (% done)", "14:07 (% done)"},
+	}
+
+	for _, tt := range tests {
+		result, err := formatDate(tt.format, base)
+		if err != nil {
+			t.Fatalf("formatDate(%q): unexpected error: %v", tt.format, err)
+		}
+		if result != tt.expected {
+			t.Errorf("formatDate(%q): expected %q, got %q", tt.format, tt.expected, result)
+		}
+	}
+
+	offset := time.Date(2024, 3, 5, 14, 7, 9, 0, time.FixedZone("CET", 3600))
+	if result, _ := formatDate("iso8601", offset); result != "2024-03-05T14:07:09+0100" {
+		t.Errorf("expected 2024-03-05T14:07:09+0100, got %s", result)
+	}
+	if result, _ := formatDate("%Y", "2024-03-15"); result != "2024" {
+		t.Errorf("expected formatDate to accept date strings, got %s", result)
+	}
+
+	if _, err := formatDate("%Y %Q", base); err == nil {
+		t.Errorf("expected error for unknown conversion")
+	}
+	if _, err := formatDate("%Y%", base); err == nil {
+		t.Errorf("expected error for trailing %%")
+	}
+	if _, err := formatDate("%Y", "someday"); err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+}
+
+func TestDateFormatTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"created": "03/15/2024 10:30 AM", "updated": 1710498600000.0}
+	tpl := `{{ .created | parseAny | formatDate "%Y-%m-%d %H:%M" }} {{ .updated | formatDate "rfc3339" }} {{ .created | formatDate "kitchen" }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "2024-03-15 10:30 2024-03-15T10:30:00Z 10:30AM"; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the time zone database, so inTZ works on systems without one.
+	_ "time/tzdata"
+)
+
+// toTime converts a helper argument to a time: times are returned as they
+// are, strings and numbers are parsed with parseAny.
+func toTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, fmt.Errorf("cannot convert nil to time")
+		}
+		return *t, nil
+	default:
+		return parseAny(v)
+	}
+}
+
+var durationPart = regexp.MustCompile(`^([0-9]*\.?[0-9]+)(ns|us|µs|ms|s|m|h|d|w)`)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// parseDuration parses a duration like Go's time.ParseDuration, but also
+// accepts days ("d") and weeks ("w"): "1w2d", "-36h", "1.5d". A day is
+// always 24 hours.
+func parseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(rest, "-") || strings.HasPrefix(rest, "+") {
+		negative = rest[0] == '-'
+		rest = rest[1:]
+	}
+	if rest == "0" {
+		return 0, nil
+	}
+	if rest == "" {
+		return 0, fmt.Errorf("invalid duration '%s'", s)
+	}
+
+	var total float64
+	for rest != "" {
+		match := durationPart.FindStringSubmatch(rest)
+		if match == nil {
+			return 0, fmt.Errorf("invalid duration '%s'", s)
+		}
+		value, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", s, err)
+		}
+		total += value * float64(durationUnits[match[2]])
+		rest = rest[len(match[0]):]
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid duration '%s': out of range", s)
+	}
+	if negative {
+		total = -total
+	}
+	return time.Duration(math.Round(total)), nil
+}
+
+// toDuration converts a helper argument to a duration: durations are
+// returned as they are and strings are parsed with parseDuration.
+func toDuration(v any) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		return parseDuration(d)
+	default:
+		return 0, fmt.Errorf("cannot convert %T to duration", v)
+	}
+}
+
+// inTZ converts a time to the given IANA time zone, like "Europe/Budapest".
+func inTZ(name string, v any) (time.Time, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("inTZ: unknown time zone '%s': %w", name, err)
+	}
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("inTZ: %w", err)
+	}
+	return t.In(loc), nil
+}
+
+func utc(v any) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("utc: %w", err)
+	}
+	return t.UTC(), nil
+}
+
+func dateAdd(d any, v any) (time.Time, error) {
+	duration, err := toDuration(d)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateAdd: %w", err)
+	}
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateAdd: %w", err)
+	}
+	return t.Add(duration), nil
+}
+
+func dateSub(d any, v any) (time.Time, error) {
+	duration, err := toDuration(d)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateSub: %w", err)
+	}
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateSub: %w", err)
+	}
+	return t.Add(-duration), nil
+}
+
+var dateDiffUnits = map[string]time.Duration{
+	"seconds": time.Second,
+	"minutes": time.Minute,
+	"hours":   time.Hour,
+	"days":    24 * time.Hour,
+	"weeks":   7 * 24 * time.Hour,
+}
+
+// dateDiff returns the time elapsed from start to end, called as
+// (start, end), so {{ .end | dateDiff .start }} reads naturally. With a unit
+// before the times ("seconds", "minutes", "hours", "days" or "weeks") the
+// number of whole units is returned instead of a duration.
+func dateDiff(args ...any) (any, error) {
+	var unit string
+	switch len(args) {
+	case 2:
+	case 3:
+		unit, _ = toString(args[0])
+		if _, ok := dateDiffUnits[unit]; !ok {
+			return nil, fmt.Errorf("dateDiff: unknown unit '%s'", unit)
+		}
+		args = args[1:]
+	default:
+		return nil, fmt.Errorf("dateDiff: expected 2 or 3 arguments, got %d", len(args))
+	}
+
+	start, err := toTime(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("dateDiff: %w", err)
+	}
+	end, err := toTime(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("dateDiff: %w", err)
+	}
+
+	diff := end.Sub(start)
+	if unit == "" {
+		return diff, nil
+	}
+	return int(diff / dateDiffUnits[unit]), nil
+}
+
+var humanUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// humanizeDuration writes a duration with its largest unit, rounded down:
+// "3 days", "1 hour".
+func humanizeDuration(d time.Duration) string {
+	d = d.Abs()
+	for _, unit := range humanUnits {
+		if n := int64(d / unit.size); n > 0 {
+			if n == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+	return "0 seconds"
+}
+
+// durationHuman describes a duration, or a time relative to now, in words:
+// a duration gives "3 days", a time "3 days ago" or "in 2 hours".
+func durationHuman(now time.Time, v any) (string, error) {
+	if d, ok := v.(time.Duration); ok {
+		return humanizeDuration(d), nil
+	}
+	if s, ok := v.(string); ok {
+		if d, err := parseDuration(s); err == nil {
+			return humanizeDuration(d), nil
+		}
+	}
+
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("durationHuman: %w", err)
+	}
+	diff := t.Sub(now)
+	switch {
+	case diff.Abs() < time.Second:
+		return "now", nil
+	case diff < 0:
+		return humanizeDuration(diff) + " ago", nil
+	default:
+		return "in " + humanizeDuration(diff), nil
+	}
+}
+
+// unixToTime converts a Unix timestamp in seconds, with an optional
+// fraction, to a time in UTC. Integers are converted exactly, without going
+// through a float.
+func unixToTime(v any) (time.Time, error) {
+	switch n := v.(type) {
+	case int:
+		return time.Unix(int64(n), 0).UTC(), nil
+	case int64:
+		return time.Unix(n, 0).UTC(), nil
+	case float64:
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC(), nil
+	case json.Number:
+		return unixToTime(string(n))
+	case string:
+		s := strings.TrimSpace(n)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(i, 0).UTC(), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot convert string to a Unix timestamp: %w", numErrorCause(err))
+		}
+		return unixToTime(f)
+	default:
+		return time.Time{}, fmt.Errorf("cannot convert %T to a Unix timestamp", v)
+	}
+}
+
+func weekday(v any) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("weekday: %w", err)
+	}
+	return t.Weekday().String(), nil
+}
+
+// isoWeek returns the ISO 8601 week number, weeks start on Monday and the
+// first week of the year is the one containing its first Thursday.
+func isoWeek(v any) (int, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return 0, fmt.Errorf("isoWeek: %w", err)
+	}
+	_, week := t.ISOWeek()
+	return week, nil
+}
+
+// truncateTime returns the start of the minute, hour, day, week (starting on
+// Monday), month, quarter or year containing t, in the time zone of t.
+func truncateTime(name, unit string, v any) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	year, month, day := t.Date()
+	loc := t.Location()
+	switch unit {
+	case "minute":
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, loc), nil
+	case "hour":
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, loc), nil
+	case "day":
+		return time.Date(year, month, day, 0, 0, 0, 0, loc), nil
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, loc), nil
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc), nil
+	case "quarter":
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, loc), nil
+	case "year":
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, loc), nil
+	default:
+		return time.Time{}, fmt.Errorf("%s: unknown unit '%s'", name, unit)
+	}
+}
+
+func startOf(unit string, v any) (time.Time, error) {
+	return truncateTime("startOf", unit, v)
+}
+
+// endOf returns the last nanosecond of the unit containing t.
+func endOf(unit string, v any) (time.Time, error) {
+	start, err := truncateTime("endOf", unit, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var next time.Time
+	switch unit {
+	case "minute":
+		next = start.Add(time.Minute)
+	case "hour":
+		next = start.Add(time.Hour)
+	case "day":
+		next = start.AddDate(0, 0, 1)
+	case "week":
+		next = start.AddDate(0, 0, 7)
+	case "month":
+		next = start.AddDate(0, 1, 0)
+	case "quarter":
+		next = start.AddDate(0, 3, 0)
+	case "year":
+		next = start.AddDate(1, 0, 0)
+	}
+	return next.Add(-time.Nanosecond), nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"72h", 72 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+		{"3d", 72 * time.Hour},
+		{"1w2d", 9 * 24 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"-2d", -48 * time.Hour},
+		{"+10s", 10 * time.Second},
+		{"250ms", 250 * time.Millisecond},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		result, err := parseDuration(tt.input)
+		if err != nil {
+			t.Fatalf("parseDuration(%q): unexpected error: %v", tt.input, err)
+		}
+		if result != tt.expected {
+			t.Errorf("parseDuration(%q): expected %v, got %v", tt.input, tt.expected, result)
+		}
+	}
+
+	for _, input := range []string{"", "-", "3", "3 days", "1y", "d", "9999999999w"} {
+		if _, err := parseDuration(input); err == nil {
+			t.Errorf("parseDuration(%q): expected error", input)
+		}
+	}
+}
+
+func TestToTime(t *testing.T) {
+	expected := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	for _, input := range []any{expected, &expected, "2024-03-15T10:30:00Z", "2024-03-15T11:30:00+01:00", 1710498600, int64(1710498600), 1710498600.0} {
+		result, err := toTime(input)
+		if err != nil {
+			t.Fatalf("toTime(%v): unexpected error: %v", input, err)
+		}
+		if !result.Equal(expected) {
+			t.Errorf("toTime(%v): expected %v, got %v", input, expected, result)
+		}
+	}
+
+	for _, input := range []any{"yesterday", nil, true, (*time.Time)(nil)} {
+		if _, err := toTime(input); err == nil {
+			t.Errorf("toTime(%v): expected error", input)
+		}
+	}
+}
+
+func TestTimeZones(t *testing.T) {
+	base := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	result, err := inTZ("Europe/Budapest", base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Format("2006-01-02 15:04 MST"); got != "2024-07-01 12:00 CEST" {
+		t.Errorf("expected 2024-07-01 12:00 CEST, got %s", got)
+	}
+
+	back, err := utc(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back != base {
+		t.Errorf("expected %v, got %v", base, back)
+	}
+
+	if _, err := inTZ("Mars/Olympus_Mons", base); err == nil {
+		t.Errorf("expected error for unknown time zone")
+	}
+	if _, err := inTZ("UTC", "not a time"); err == nil {
+		t.Errorf("expected error for invalid time")
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	added, err := dateAdd("72h", base)
+	if err != nil || !added.Equal(base.Add(72*time.Hour)) {
+		t.Errorf("dateAdd: expected %v, got %v, %v", base.Add(72*time.Hour), added, err)
+	}
+	added, err = dateAdd(time.Hour, "2024-03-15T10:30:00Z")
+	if err != nil || !added.Equal(base.Add(time.Hour)) {
+		t.Errorf("dateAdd: expected %v, got %v, %v", base.Add(time.Hour), added, err)
+	}
+	subtracted, err := dateSub("1w", base)
+	if err != nil || !subtracted.Equal(base.AddDate(0, 0, -7)) {
+		t.Errorf("dateSub: expected %v, got %v, %v", base.AddDate(0, 0, -7), subtracted, err)
+	}
+	if _, err := dateAdd("soon", base); err == nil {
+		t.Errorf("expected error for invalid duration")
+	}
+	if _, err := dateSub(3, base); err == nil {
+		t.Errorf("expected error for number duration")
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	diffTests := []struct {
+		args     []any
+		expected any
+	}{
+		{[]any{start, base}, base.Sub(start)},
+		{[]any{"days", start, base}, 74},
+		{[]any{"weeks", start, base}, 10},
+		{[]any{"hours", base, start}, -1786},
+	}
+	for _, tt := range diffTests {
+		result, err := dateDiff(tt.args...)
+		if err != nil {
+			t.Fatalf("dateDiff(%v): unexpected error: %v", tt.args, err)
+		}
+		if result != tt.expected {
+			t.Errorf("dateDiff(%v): expected %v, got %v", tt.args, tt.expected, result)
+		}
+	}
+	if _, err := dateDiff("months", start, base); err == nil {
+		t.Errorf("expected error for unknown unit")
+	}
+	if _, err := dateDiff(start); err == nil {
+		t.Errorf("expected error for missing argument")
+	}
+}
+
+func TestDurationHuman(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{72 * time.Hour, "3 days"},
+		{"50h", "2 days"},
+		{time.Hour, "1 hour"},
+		{-90 * time.Second, "1 minute"},
+		{400 * 24 * time.Hour, "1 year"},
+		{"14d", "2 weeks"},
+		{60 * 24 * time.Hour, "2 months"},
+		{500 * time.Millisecond, "0 seconds"},
+		{now.Add(-73 * time.Hour), "3 days ago"},
+		{now.Add(2*time.Hour + time.Minute), "in 2 hours"},
+		{"2024-03-15T11:30:00+01:00", "now"},
+		{"2023-03-15", "1 year ago"},
+	}
+
+	for _, tt := range tests {
+		result, err := durationHuman(now, tt.input)
+		if err != nil {
+			t.Fatalf("durationHuman(%v): unexpected error: %v", tt.input, err)
+		}
+		if result != tt.expected {
+			t.Errorf("durationHuman(%v): expected %s, got %s", tt.input, tt.expected, result)
+		}
+	}
+
+	if _, err := durationHuman(now, "whenever"); err == nil {
+		t.Errorf("expected error for invalid input")
+	}
+}
+
+func TestUnixToTime(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected time.Time
+	}{
+		{1700000000, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{int64(0), time.Unix(0, 0).UTC()},
+		{1700000000.5, time.Date(2023, 11, 14, 22, 13, 20, 500000000, time.UTC)},
+		{"1700000000", time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{json.Number("9007199254740993"), time.Unix(9007199254740993, 0).UTC()},
+	}
+	for _, tt := range tests {
+		result, err := unixToTime(tt.input)
+		if err != nil {
+			t.Fatalf("unixToTime(%v): unexpected error: %v", tt.input, err)
+		}
+		if result != tt.expected {
+			t.Errorf("unixToTime(%v): expected %v, got %v", tt.input, tt.expected, result)
+		}
+	}
+
+	if _, err := unixToTime("soon"); err == nil || strings.Contains(err.Error(), "soon") {
+		t.Errorf("expected error for invalid timestamp without the value, got %v", err)
+	}
+}
+
+func TestCalendarHelpers(t *testing.T) {
+	base := time.Date(2024, 12, 30, 15, 45, 30, 0, time.UTC)
+
+	if day, err := weekday(base); err != nil || day != "Monday" {
+		t.Errorf("weekday: expected Monday, got %s, %v", day, err)
+	}
+	if week, err := isoWeek(base); err != nil || week != 1 {
+		t.Errorf("isoWeek: expected 1, got %d, %v", week, err)
+	}
+	if week, err := isoWeek("2024-03-15T10:30:00Z"); err != nil || week != 11 {
+		t.Errorf("isoWeek: expected 11, got %d, %v", week, err)
+	}
+
+	tests := []struct {
+		unit       string
+		start, end string
+	}{
+		{"minute", "2024-12-30T15:45:00Z", "2024-12-30T15:45:59.999999999Z"},
+		{"hour", "2024-12-30T15:00:00Z", "2024-12-30T15:59:59.999999999Z"},
+		{"day", "2024-12-30T00:00:00Z", "2024-12-30T23:59:59.999999999Z"},
+		{"week", "2024-12-30T00:00:00Z", "2025-01-05T23:59:59.999999999Z"},
+		{"month", "2024-12-01T00:00:00Z", "2024-12-31T23:59:59.999999999Z"},
+		{"quarter", "2024-10-01T00:00:00Z", "2024-12-31T23:59:59.999999999Z"},
+		{"year", "2024-01-01T00:00:00Z", "2024-12-31T23:59:59.999999999Z"},
+	}
+	for _, tt := range tests {
+		start, err := startOf(tt.unit, base)
+		if err != nil {
+			t.Fatalf("startOf(%q): unexpected error: %v", tt.unit, err)
+		}
+		if got := start.Format(time.RFC3339Nano); got != tt.start {
+			t.Errorf("startOf(%q): expected %s, got %s", tt.unit, tt.start, got)
+		}
+		end, err := endOf(tt.unit, base)
+		if err != nil {
+			t.Fatalf("endOf(%q): unexpected error: %v", tt.unit, err)
+		}
+		if got := end.Format(time.RFC3339Nano); got != tt.end {
+			t.Errorf("endOf(%q): expected %s, got %s", tt.unit, tt.end, got)
+		}
+	}
+
+	sunday := time.Date(2024, 12, 29, 12, 0, 0, 0, time.UTC)
+	if start, _ := startOf("week", sunday); start.Day() != 23 {
+		t.Errorf("expected the week of a Sunday to start on Monday the 23rd, got %v", start)
+	}
+
+	budapest, _ := time.LoadLocation("Europe/Budapest")
+	local := time.Date(2024, 3, 31, 12, 0, 0, 0, budapest)
+	if start, _ := startOf("day", local); start.Format(time.RFC3339) != "2024-03-31T00:00:00+01:00" {
+		t.Errorf("expected the start of the day in the time zone of the time, got %v", start)
+	}
+
+	if _, err := startOf("decade", base); err == nil {
+		t.Errorf("expected error for unknown unit")
+	}
+	if _, err := endOf("decade", base); err == nil {
+		t.Errorf("expected error for unknown unit")
+	}
+}
+
+func TestDateTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"created": "2024-03-15T10:30:00Z", "ts": 1710498600.0}
+	tpl := `{{ .created | inTZ "Europe/Budapest" | formatDate "2006-01-02 15:04" }} {{ .created | dateAdd "3d" | weekday }} {{ .ts | unixToTime | startOf "month" | formatDate "Jan 2" }} {{ dateDiff "days" "2024-03-01T00:00:00Z" .created }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "2024-03-15 11:30 Monday Mar 1 14"; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
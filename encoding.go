@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+)
+
+// The encoders accept any value like the hash helpers, see toBytes. The
+// decoders return the decoded bytes as a string.
+
+func base64Encode(v any) string {
+	return base64.StdEncoding.EncodeToString(toBytes(v))
+}
+
+func base64Decode(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return string(data), nil
+}
+
+// base64URLEncode encodes with the URL and file name safe alphabet of
+// RFC 4648, "-" and "_" instead of "+" and "/", with padding.
+func base64URLEncode(v any) string {
+	return base64.URLEncoding.EncodeToString(toBytes(v))
+}
+
+// base64RawEncode encodes with the standard alphabet, without padding.
+func base64RawEncode(v any) string {
+	return base64.RawStdEncoding.EncodeToString(toBytes(v))
+}
+
+// urlSafeBase64 encodes with the URL safe alphabet without padding, as JWTs
+// and many URL tokens are written.
+func urlSafeBase64(v any) string {
+	return base64.RawURLEncoding.EncodeToString(toBytes(v))
+}
+
+// decodeUnpadded decodes base64 with or without padding.
+func decodeUnpadded(name string, enc *base64.Encoding, s string) (string, error) {
+	data, err := enc.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// base64URLDecode decodes URL safe base64, from base64URLEncode and
+// urlSafeBase64.
+func base64URLDecode(s string) (string, error) {
+	return decodeUnpadded("URL-safe base64", base64.RawURLEncoding, s)
+}
+
+func base64RawDecode(s string) (string, error) {
+	return decodeUnpadded("base64", base64.RawStdEncoding, s)
+}
+
+func base32Encode(v any) string {
+	return base32.StdEncoding.EncodeToString(toBytes(v))
+}
+
+func base32Decode(s string) (string, error) {
+	data, err := base32.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base32: %w", err)
+	}
+	return string(data), nil
+}
+
+func hexEncode(v any) string {
+	return hex.EncodeToString(toBytes(v))
+}
+
+func hexDecode(s string) (string, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode hex: %w", err)
+	}
+	return string(data), nil
+}
+
+// gzipCompress compresses with gzip, the result is binary and has to be
+// encoded: {{ .config | gzip | base64Encode }}. The header has no name or
+// time, so the same input always gives the same output.
+func gzipCompress(v any) (string, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(toBytes(v)); err != nil {
+		return "", fmt.Errorf("gzip: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("gzip: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func gunzip(v any) (string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(toBytes(v)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress gzip: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress gzip: %w", err)
+	}
+	return string(data), nil
+}
+
+// quotedPrintable encodes for email bodies (RFC 2045): bytes outside
+// printable ASCII are written as "=XX" and lines are wrapped at 76
+// characters with soft line breaks.
+func quotedPrintable(v any) (string, error) {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(toBytes(v)); err != nil {
+		return "", fmt.Errorf("quotedPrintable: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("quotedPrintable: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// quotedPrintableDecode decodes quoted-printable text. Like most mail
+// readers it keeps invalid escapes as they are.
+func quotedPrintableDecode(s string) (string, error) {
+	data, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(s)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode quoted-printable: %w", err)
+	}
+	return string(data), nil
+}
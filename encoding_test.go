@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncoders(t *testing.T) {
+	binary := string([]byte{0xfb, 0xff, 0xfe, 'a'})
+	tests := []struct {
+		name     string
+		fn       func(any) string
+		input    any
+		expected string
+	}{
+		{"base64Encode", base64Encode, "hello?>", "aGVsbG8/Pg=="},
+		{"base64Encode bytes", base64Encode, []byte("hello"), "aGVsbG8="},
+		{"base64Encode number", base64Encode, 42.0, "NDI="},
+		{"base64URLEncode", base64URLEncode, "hello?>", "aGVsbG8_Pg=="},
+		{"base64URLEncode binary", base64URLEncode, binary, "-__-YQ=="},
+		{"base64RawEncode", base64RawEncode, "hello?>", "aGVsbG8/Pg"},
+		{"urlSafeBase64", urlSafeBase64, binary, "-__-YQ"},
+		{"base32Encode", base32Encode, "hello", "NBSWY3DP"},
+		{"base32Encode padded", base32Encode, "hi", "NBUQ===="},
+		{"hexEncode", hexEncode, "hello", "68656c6c6f"},
+		{"hexEncode binary", hexEncode, binary, "fbfffe61"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.fn(tt.input); result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecoders(t *testing.T) {
+	binary := string([]byte{0xfb, 0xff, 0xfe, 'a'})
+	tests := []struct {
+		name     string
+		fn       func(string) (string, error)
+		input    string
+		expected string
+	}{
+		{"base64Decode", base64Decode, "aGVsbG8/Pg==", "hello?>"},
+		{"base64URLDecode", base64URLDecode, "-__-YQ==", binary},
+		{"base64URLDecode unpadded", base64URLDecode, "-__-YQ", binary},
+		{"base64RawDecode", base64RawDecode, "aGVsbG8/Pg", "hello?>"},
+		{"base64RawDecode padded", base64RawDecode, "aGVsbG8/Pg==", "hello?>"},
+		{"base32Decode", base32Decode, "NBUQ====", "hi"},
+		{"hexDecode", hexDecode, "68656C6C6F", "hello"},
+		{"quotedPrintableDecode", quotedPrintableDecode, "caf=C3=A9 =\r\nau lait", "café au lait"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name  string
+		fn    func(string) (string, error)
+		input string
+	}{
+		{"base64Decode", base64Decode, "aGVsbG8"},
+		{"base64URLDecode", base64URLDecode, "aGVsbG8/Pg"},
+		{"base64RawDecode", base64RawDecode, "-__-YQ"},
+		{"base32Decode", base32Decode, "nbswy3dp"},
+		{"hexDecode odd", hexDecode, "abc"},
+		{"hexDecode invalid", hexDecode, "zz"},
+	}
+	for _, tt := range errorCases {
+		if _, err := tt.fn(tt.input); err == nil || !strings.HasPrefix(err.Error(), "failed to decode") {
+			t.Errorf("%s(%q): expected a decoding error, got %v", tt.name, tt.input, err)
+		}
+	}
+}
+
+func TestGzip(t *testing.T) {
+	input := strings.Repeat("key: value\n", 100)
+	compressed, err := gzipCompress(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(compressed, "\x1f\x8b") || len(compressed) >= len(input) {
+		t.Errorf("expected gzip data smaller than the input, got %d bytes", len(compressed))
+	}
+	if again, _ := gzipCompress(input); again != compressed {
+		t.Errorf("expected the same output for the same input")
+	}
+
+	result, err := gunzip(compressed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != input {
+		t.Errorf("expected the input back, got %q", result)
+	}
+
+	if _, err := gunzip("not gzip"); err == nil {
+		t.Errorf("expected error for invalid gzip data")
+	}
+	if _, err := gunzip(compressed[:len(compressed)-4]); err == nil {
+		t.Errorf("expected error for truncated gzip data")
+	}
+}
+
+func TestQuotedPrintable(t *testing.T) {
+	input := "Árvíztűrő tükörfúrógép = " + strings.Repeat("x", 80)
+	encoded, err := quotedPrintable(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "=C3=81rv=C3=ADzt=C5=B1r=C5=91") || !strings.Contains(encoded, " =3D ") {
+		t.Errorf("unexpected encoding %q", encoded)
+	}
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("expected lines of at most 76 characters, got %q", line)
+		}
+	}
+	if decoded, _ := quotedPrintableDecode(encoded); decoded != input {
+		t.Errorf("expected the input back, got %q", decoded)
+	}
+}
+
+func TestEncodingTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"config": "a: 1\nb: 2\n"}
+	tpl := `{{ .config | gzip | base64Encode | base64Decode | gunzip | hexEncode | hexDecode | base32Encode | base32Decode | urlSafeBase64 | urlSafeBase64Decode }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != data["config"] {
+		t.Errorf("expected the config back, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := executeTemplate(&buf, `{{ hexDecode "zz" }}`, nil, renderConfig{}); err == nil {
+		t.Errorf("expected error for invalid hex")
+	}
+}
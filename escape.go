@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// shellQuote quotes a value as a single POSIX shell word. The value is put
+// between single quotes, in which the shell does not expand anything. A
+// single quote closes the quoted part, is written escaped with a backslash
+// and a new quoted part is opened.
+func shellQuote(v any) (string, error) {
+	s, _ := toString(v)
+	if strings.ContainsRune(s, 0) {
+		return "", fmt.Errorf("shellQuote: value contains a NUL byte")
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'", nil
+}
+
+// sqlQuote quotes a value as a standard SQL string literal by doubling the
+// single quotes. Backslashes are not special in standard SQL, so the result
+// is meant for databases following the standard (PostgreSQL, SQLite, MySQL
+// with NO_BACKSLASH_ESCAPES). nil becomes NULL.
+func sqlQuote(v any) (string, error) {
+	if v == nil {
+		return "NULL", nil
+	}
+	s, _ := toString(v)
+	if strings.ContainsRune(s, 0) {
+		return "", fmt.Errorf("sqlQuote: value contains a NUL byte")
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+}
+
+// sqlIdent quotes a table or column name as a SQL identifier by putting it
+// between double quotes and doubling the double quotes in it.
+func sqlIdent(v any) (string, error) {
+	s, _ := toString(v)
+	if s == "" {
+		return "", fmt.Errorf("sqlIdent: identifier must not be empty")
+	}
+	if strings.ContainsRune(s, 0) {
+		return "", fmt.Errorf("sqlIdent: identifier contains a NUL byte")
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`, nil
+}
+
+// urlQueryEncode escapes a value to be used as a query parameter name or
+// value, spaces become "+".
+func urlQueryEncode(v any) string {
+	s, _ := toString(v)
+	return url.QueryEscape(s)
+}
+
+func urlQueryDecode(s string) (string, error) {
+	result, err := url.QueryUnescape(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode URL query: %w", err)
+	}
+	return result, nil
+}
+
+// urlPathEncode escapes a value to be used as a single path segment, so
+// slashes are escaped too.
+func urlPathEncode(v any) string {
+	s, _ := toString(v)
+	return url.PathEscape(s)
+}
+
+func urlPathDecode(s string) (string, error) {
+	result, err := url.PathUnescape(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode URL path: %w", err)
+	}
+	return result, nil
+}
+
+// jsonString returns a value as a quoted JSON string literal. HTML special
+// characters are escaped as well, so the result can be embedded in a script
+// tag.
+func jsonString(v any) (string, error) {
+	s, _ := toString(v)
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	return string(data), nil
+}
+
+// xmlEscape escapes a value for XML text and attribute values. Characters
+// not allowed in XML are replaced with U+FFFD.
+func xmlEscape(v any) string {
+	s, _ := toString(v)
+	var buf bytes.Buffer
+	// EscapeText only fails if the writer fails, which bytes.Buffer doesn't.
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// regexQuote escapes all regular expression metacharacters, so the result
+// matches the value literally.
+func regexQuote(v any) string {
+	s, _ := toString(v)
+	return regexp.QuoteMeta(s)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// hostileInputs are strings trying to break out of the quoting.
+var hostileInputs = []string{
+	"",
+	"plain",
+	"it's",
+	"'; DROP TABLE users; --",
+	`Robert"); DROP TABLE students;--`,
+	"$(rm -rf /)",
+	"`id`",
+	"${HOME}",
+	`a'b"c\d`,
+	`\'`,
+	"''''",
+	"line1\nline2\r\n",
+	"tab\there",
+	"Árvíztűrő 日本 👍",
+	"<script>alert(1)</script>",
+	"]]><![CDATA[",
+	"&amp; &lt;",
+	"a b+c%20d/e?f=g&h#i",
+	"%zz",
+	"../../etc/passwd",
+	`.*+?()[]{}|^$\`,
+	"  ",
+}
+
+func TestShellQuote(t *testing.T) {
+	sh, _ := exec.LookPath("sh")
+	if sh == "" {
+		t.Logf("sh not found, round trip through the shell skipped")
+	}
+	for _, input := range hostileInputs {
+		quoted, err := shellQuote(input)
+		if err != nil {
+			t.Fatalf("shellQuote(%q): unexpected error: %v", input, err)
+		}
+		if !strings.HasPrefix(quoted, "'") || !strings.HasSuffix(quoted, "'") {
+			t.Errorf("shellQuote(%q): expected single quoted result, got %s", input, quoted)
+		}
+		if sh == "" {
+			continue
+		}
+		out, err := exec.Command(sh, "-c", "printf '%s' "+quoted).Output()
+		if err != nil {
+			t.Fatalf("shellQuote(%q): shell failed: %v", input, err)
+		}
+		if string(out) != input {
+			t.Errorf("shellQuote(%q): shell got %q", input, out)
+		}
+	}
+
+	if _, err := shellQuote("a\x00b"); err == nil {
+		t.Errorf("expected error for NUL byte")
+	}
+	if result, _ := shellQuote(8080); result != "'8080'" {
+		t.Errorf("expected '8080', got %s", result)
+	}
+}
+
+// parseSQLQuoted reads a quoted SQL literal or identifier and returns its
+// value and whatever follows it.
+func parseSQLQuoted(s string, quote byte) (string, string, bool) {
+	if len(s) == 0 || s[0] != quote {
+		return "", s, false
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != quote {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			b.WriteByte(quote)
+			i++
+			continue
+		}
+		return b.String(), s[i+1:], true
+	}
+	return "", "", false
+}
+
+func TestSQLQuote(t *testing.T) {
+	for _, input := range hostileInputs {
+		quoted, err := sqlQuote(input)
+		if err != nil {
+			t.Fatalf("sqlQuote(%q): unexpected error: %v", input, err)
+		}
+		value, rest, ok := parseSQLQuoted(quoted, '\'')
+		if !ok || rest != "" || value != input {
+			t.Errorf("sqlQuote(%q): %s does not round trip, got %q, rest %q", input, quoted, value, rest)
+		}
+
+		if input == "" {
+			continue
+		}
+		ident, err := sqlIdent(input)
+		if err != nil {
+			t.Fatalf("sqlIdent(%q): unexpected error: %v", input, err)
+		}
+		value, rest, ok = parseSQLQuoted(ident, '"')
+		if !ok || rest != "" || value != input {
+			t.Errorf("sqlIdent(%q): %s does not round trip, got %q, rest %q", input, ident, value, rest)
+		}
+	}
+
+	if result, _ := sqlQuote(nil); result != "NULL" {
+		t.Errorf("expected NULL, got %s", result)
+	}
+	if result, _ := sqlQuote("it's"); result != "'it''s'" {
+		t.Errorf("expected 'it''s', got %s", result)
+	}
+	if _, err := sqlQuote("a\x00b"); err == nil {
+		t.Errorf("expected error for NUL byte")
+	}
+	if _, err := sqlIdent(""); err == nil {
+		t.Errorf("expected error for empty identifier")
+	}
+}
+
+func TestURLEncoding(t *testing.T) {
+	for _, input := range hostileInputs {
+		query := urlQueryEncode(input)
+		if strings.ContainsAny(query, " &=?#/") {
+			t.Errorf("urlQueryEncode(%q): unescaped characters in %s", input, query)
+		}
+		if decoded, err := urlQueryDecode(query); err != nil || decoded != input {
+			t.Errorf("urlQueryEncode(%q): round trip gave %q, %v", input, decoded, err)
+		}
+
+		path := urlPathEncode(input)
+		if strings.ContainsAny(path, " /?#") {
+			t.Errorf("urlPathEncode(%q): unescaped characters in %s", input, path)
+		}
+		if decoded, err := urlPathDecode(path); err != nil || decoded != input {
+			t.Errorf("urlPathEncode(%q): round trip gave %q, %v", input, decoded, err)
+		}
+	}
+
+	if result := urlQueryEncode("a b&c"); result != "a+b%26c" {
+		t.Errorf("expected a+b%%26c, got %s", result)
+	}
+	if result := urlPathEncode("a b/c"); result != "a%20b%2Fc" {
+		t.Errorf("expected a%%20b%%2Fc, got %s", result)
+	}
+	if _, err := urlQueryDecode("%zz"); err == nil {
+		t.Errorf("expected error for invalid escape")
+	}
+	if _, err := urlPathDecode("%"); err == nil {
+		t.Errorf("expected error for invalid escape")
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	for _, input := range hostileInputs {
+		quoted, err := jsonString(input)
+		if err != nil {
+			t.Fatalf("jsonString(%q): unexpected error: %v", input, err)
+		}
+		if strings.ContainsAny(quoted, "<>\n") {
+			t.Errorf("jsonString(%q): unescaped characters in %s", input, quoted)
+		}
+		var decoded string
+		if err := json.Unmarshal([]byte(quoted), &decoded); err != nil || decoded != input {
+			t.Errorf("jsonString(%q): round trip gave %q, %v", input, decoded, err)
+		}
+	}
+
+	if result, _ := jsonString(42); result != `"42"` {
+		t.Errorf(`expected "42", got %s`, result)
+	}
+}
+
+func TestXMLEscape(t *testing.T) {
+	for _, input := range hostileInputs {
+		escaped := xmlEscape(input)
+		doc := `<a b="` + escaped + `">` + escaped + `</a>`
+		var v struct {
+			B    string `xml:"b,attr"`
+			Text string `xml:",chardata"`
+		}
+		if err := xml.Unmarshal([]byte(doc), &v); err != nil {
+			t.Fatalf("xmlEscape(%q): invalid XML %s: %v", input, doc, err)
+		}
+		if v.B != input || v.Text != input {
+			t.Errorf("xmlEscape(%q): round trip gave %q and %q", input, v.B, v.Text)
+		}
+	}
+
+	if result := xmlEscape("a\x00b"); result != "a�b" {
+		t.Errorf("expected invalid character to be replaced, got %q", result)
+	}
+}
+
+func TestRegexQuote(t *testing.T) {
+	for _, input := range hostileInputs {
+		re, err := regexp.Compile("^" + regexQuote(input) + "$")
+		if err != nil {
+			t.Fatalf("regexQuote(%q): invalid pattern: %v", input, err)
+		}
+		if !re.MatchString(input) {
+			t.Errorf("regexQuote(%q): pattern %s does not match the input", input, re)
+		}
+	}
+
+	if regexp.MustCompile(regexQuote("a.c")).MatchString("abc") {
+		t.Errorf("expected the quoted dot to match only a dot")
+	}
+}
+
+func TestEscapeTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"name": "it's", "table": `my"table`, "q": "a b"}
+	tpl := `echo {{ shellQuote .name }}; SELECT * FROM {{ sqlIdent .table }} WHERE name = {{ sqlQuote .name }}; ?q={{ urlQueryEncode .q }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `echo 'it'\''s'; SELECT * FROM "my""table" WHERE name = 'it''s'; ?q=a+b`
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
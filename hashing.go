@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/fnv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// toBytes converts the argument of a hashing helper to bytes: byte slices
+// are used as they are, other values are converted with toString.
+func toBytes(v any) []byte {
+	if b, ok := v.([]byte); ok {
+		return b
+	}
+	s, _ := toString(v)
+	return []byte(s)
+}
+
+func hashHex(h hash.Hash, v any) string {
+	h.Write(toBytes(v))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func md5Sum(v any) string {
+	return hashHex(md5.New(), v)
+}
+
+func sha1Sum(v any) string {
+	return hashHex(sha1.New(), v)
+}
+
+func sha256Sum(v any) string {
+	return hashHex(sha256.New(), v)
+}
+
+func sha384Sum(v any) string {
+	return hashHex(sha512.New384(), v)
+}
+
+func sha512Sum(v any) string {
+	return hashHex(sha512.New(), v)
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of a message with a key, in hex, called
+// as (key, message) so the message can be piped.
+func hmacSHA256(key, msg any) string {
+	return hashHex(hmac.New(sha256.New, toBytes(key)), msg)
+}
+
+// crc32Sum returns the IEEE CRC-32 checksum, in hex.
+func crc32Sum(v any) string {
+	return hashHex(crc32.NewIEEE(), v)
+}
+
+// fnvSum returns the FNV-1a hash, in hex, of 32 bits, or of the size given
+// before the value: 32, 64 or 128.
+func fnvSum(args ...any) (string, error) {
+	var h hash.Hash
+	switch len(args) {
+	case 1:
+		h = fnv.New32a()
+	case 2:
+		bits, err := toInt(args[0])
+		if err != nil {
+			return "", fmt.Errorf("fnv: invalid size: %w", err)
+		}
+		switch bits {
+		case 32:
+			h = fnv.New32a()
+		case 64:
+			h = fnv.New64a()
+		case 128:
+			h = fnv.New128a()
+		default:
+			return "", fmt.Errorf("fnv: unknown size %d, expected 32, 64 or 128", bits)
+		}
+		args = args[1:]
+	default:
+		return "", fmt.Errorf("fnv: expected 1 or 2 arguments, got %d", len(args))
+	}
+	return hashHex(h, args[0]), nil
+}
+
+func bcryptGenerate(name string, cost int, password any) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(toBytes(password), cost)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return string(hashed), nil
+}
+
+// bcryptHash hashes a password with bcrypt, with the cost given before the
+// password or 10 by default. The salt is always read from crypto/rand, so
+// the hash differs on every render, even with --seed.
+func bcryptHash(args ...any) (string, error) {
+	cost := bcrypt.DefaultCost
+	switch len(args) {
+	case 1:
+	case 2:
+		var err error
+		if cost, err = toInt(args[0]); err != nil {
+			return "", fmt.Errorf("bcrypt: invalid cost: %w", err)
+		}
+		args = args[1:]
+	default:
+		return "", fmt.Errorf("bcrypt: expected 1 or 2 arguments, got %d", len(args))
+	}
+	return bcryptGenerate("bcrypt", cost, args[0])
+}
+
+// htpasswd returns an htpasswd line for a user with a bcrypt hashed
+// password, as "htpasswd -B" writes it, for Apache and nginx basic
+// authentication.
+func htpasswd(user, password any) (string, error) {
+	name, _ := toString(user)
+	if name == "" || strings.ContainsAny(name, ":\n") {
+		return "", fmt.Errorf("htpasswd: invalid user name '%s'", name)
+	}
+	hashed, err := bcryptGenerate("htpasswd", bcrypt.DefaultCost, password)
+	if err != nil {
+		return "", err
+	}
+	return name + ":$2y$" + strings.TrimPrefix(hashed, "$2a$"), nil
+}
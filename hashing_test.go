@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashes(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(any) string
+		input    any
+		expected string
+	}{
+		{"md5", md5Sum, "hello", "5d41402abc4b2a76b9719d911017c592"},
+		{"md5 bytes", md5Sum, []byte{0, 255}, "d07d34efac6328007ad67c7e0a985e00"},
+		{"sha1", sha1Sum, "hello", "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"},
+		{"sha256", sha256Sum, "hello", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{"sha256 number", sha256Sum, 42.0, "73475cb40a568e8da8a045ced110137e159f890ac4da883b6b17dc651b3a8049"},
+		{"sha256 nil", sha256Sum, nil, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha384", sha384Sum, "hello", "59e1748777448c69de6b800d7a33bbfb9ff1b463e44354c3553bcdb9c666fa90125a3c79f90397bdf5f6a13de828684f"},
+		{"sha512", sha512Sum, "hello", "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043"},
+		{"crc32", crc32Sum, "hello", "3610a686"},
+		{"crc32 bytes", crc32Sum, []byte("hello"), "3610a686"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.fn(tt.input); result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHMACSHA256(t *testing.T) {
+	result := hmacSHA256("key", "The quick brown fox jumps over the lazy dog")
+	if expected := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"; result != expected {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+	if hmacSHA256([]byte("key"), "msg") != hmacSHA256("key", []byte("msg")) {
+		t.Errorf("expected strings and bytes to give the same HMAC")
+	}
+}
+
+func TestFNV(t *testing.T) {
+	tests := []struct {
+		args     []any
+		expected string
+	}{
+		{[]any{"hello"}, "4f9f2cab"},
+		{[]any{32, "hello"}, "4f9f2cab"},
+		{[]any{64.0, "hello"}, "a430d84680aabd0b"},
+		{[]any{"128", "hello"}, "e3e1efd54283d94f7081314b599d31b3"},
+	}
+	for _, tt := range tests {
+		result, err := fnvSum(tt.args...)
+		if err != nil {
+			t.Fatalf("fnv(%v): unexpected error: %v", tt.args, err)
+		}
+		if result != tt.expected {
+			t.Errorf("fnv(%v): expected %s, got %s", tt.args, tt.expected, result)
+		}
+	}
+
+	for _, args := range [][]any{{}, {16, "hello"}, {"big", "hello"}, {32, "a", "b"}} {
+		if _, err := fnvSum(args...); err == nil {
+			t.Errorf("fnv(%v): expected error", args)
+		}
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	hashed, err := bcryptHash(bcrypt.MinCost, "s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(hashed, "$2a$04$") {
+		t.Errorf("expected a bcrypt hash with cost 4, got %s", hashed)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte("s3cret")); err != nil {
+		t.Errorf("expected the hash to match the password: %v", err)
+	}
+
+	hashed, err = bcryptHash("s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(hashed)); cost != bcrypt.DefaultCost {
+		t.Errorf("expected the default cost, got %d", cost)
+	}
+
+	if _, err := bcryptHash(99, "s3cret"); err == nil {
+		t.Errorf("expected error for an invalid cost")
+	}
+	if _, err := bcryptHash(strings.Repeat("x", 73)); err == nil {
+		t.Errorf("expected error for a password longer than 72 bytes")
+	}
+	if _, err := bcryptHash(); err == nil {
+		t.Errorf("expected error for missing password")
+	}
+}
+
+func TestHtpasswd(t *testing.T) {
+	line, err := htpasswd("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	user, hashed, _ := strings.Cut(line, ":")
+	if user != "admin" || !strings.HasPrefix(hashed, "$2y$10$") {
+		t.Errorf("expected an htpasswd line with a $2y$ bcrypt hash, got %s", line)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte("s3cret")); err != nil {
+		t.Errorf("expected the hash to match the password: %v", err)
+	}
+
+	for _, user := range []string{"", "ad:min", "admin\n"} {
+		if _, err := htpasswd(user, "s3cret"); err == nil {
+			t.Errorf("htpasswd(%q): expected error", user)
+		}
+	}
+}
+
+func TestHashingTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"id": 42.0, "secret": "key", "body": "The quick brown fox jumps over the lazy dog"}
+	tpl := `{{ sha256 .id }} {{ .body | hmacSHA256 .secret }} {{ crc32 "hello" }} {{ fnv 64 "hello" }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "73475cb40a568e8da8a045ced110137e159f890ac4da883b6b17dc651b3a8049 f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8 3610a686 a430d84680aabd0b"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -1,33 +1,63 @@
 package main
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
+	"math"
+	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 )
 
+// errIntRange is returned by toInt for numbers outside the range of int,
+// which would wrap around if converted.
+var errIntRange = errors.New("cannot convert number to int: out of range")
+
+// floatToInt truncates a float to an int. Only the fraction may be lost:
+// floats outside the range of int, infinities and NaN are errors.
+func floatToInt(f float64) (int, error) {
+	if math.IsNaN(f) || f < math.MinInt || f >= -math.MinInt {
+		return 0, errIntRange
+	}
+	return int(f), nil
+}
+
 func toInt(s any) (int, error) {
 	switch v := s.(type) {
 	case int:
 		return v, nil
 	case int64:
+		if v < math.MinInt || v > math.MaxInt {
+			return 0, errIntRange
+		}
 		return int(v), nil
 	case float64:
-		return int(v), nil
+		return floatToInt(v)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 0); err == nil {
+			return int(i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert number to int: %w", numErrorCause(err))
+		}
+		return floatToInt(f)
+	case *big.Int:
+		if !v.IsInt64() || v.Int64() < math.MinInt || v.Int64() > math.MaxInt {
+			return 0, errIntRange
+		}
+		return int(v.Int64()), nil
 	case string:
 		var i int
 		_, err := fmt.Sscanf(v, "%d", &i)
 		if err != nil {
-			return 0, fmt.Errorf("cannot convert string '%s' to int: %w", v, err)
+			return 0, fmt.Errorf("cannot convert string to int: %w", numErrorCause(err))
 		}
 		return i, nil
 	default:
@@ -45,11 +75,20 @@ func toFloat(s any) (float64, error) {
 		return float64(v), nil
 	case int64:
 		return float64(v), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert number to float: %w", numErrorCause(err))
+		}
+		return f, nil
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f, nil
 	case string:
 		var f float64
 		_, err := fmt.Sscanf(v, "%f", &f)
 		if err != nil {
-			return 0, fmt.Errorf("cannot convert string '%s' to float: %w", v, err)
+			return 0, fmt.Errorf("cannot convert string to float: %w", numErrorCause(err))
 		}
 		return f, nil
 	default:
@@ -57,6 +96,16 @@ func toFloat(s any) (float64, error) {
 	}
 }
 
+// numErrorCause returns the cause of a strconv error, without the input it
+// quotes, so the converted values stay out of the error messages.
+func numErrorCause(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
 func toFloatPair(a any, b any) (float64, float64, error) {
 	aFloat, err := toFloat(a)
 	if err != nil {
@@ -97,8 +146,28 @@ func toString(x any) (string, error) {
 	}
 }
 
-// Helper functions for the template engine
-func createHelperFuncs() template.FuncMap {
+func isEmpty(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	default:
+		return false
+	}
+}
+
+// Helper functions for the template engine. The time helpers take the
+// current time and the random helpers their seed from config.
+func createHelperFuncs(config renderConfig) template.FuncMap {
+	regexps := newRegexpCache()
+	random := newRandomSource(config)
+	numbers := numberConverter{strict: config.strictNumbers}
+
 	return template.FuncMap{
 		// String manipulation
 		"upper": strings.ToUpper,
@@ -125,14 +194,43 @@ func createHelperFuncs() template.FuncMap {
 		"repeat": func(count int, s string) string {
 			return strings.Repeat(s, count)
 		},
+		"runeLen":  runeLen,
+		"width":    width,
+		"truncate": truncate,
+		"padLeft":  padLeft,
+		"padRight": padRight,
+		"center":   center,
+		"substr":   substr,
+		"table":    table,
+
+		// Markdown
+		"markdown":      markdown,
+		"stripMarkdown": stripMarkdown,
+
+		// Case conversion
+		"camelCase":      camelCase,
+		"pascalCase":     pascalCase,
+		"snakeCase":      snakeCase,
+		"kebabCase":      kebabCase,
+		"screamingSnake": screamingSnake,
+		"title":          title,
+		"slugify":        slugify,
+		"pluralize":      pluralize,
+		"singularize":    singularize,
+		"initials":       initials,
+
+		// Regular expressions
+		"regexMatch":    regexps.match,
+		"regexFind":     regexps.find,
+		"regexFindAll":  regexps.findAll,
+		"regexReplace":  regexps.replace,
+		"regexSplit":    regexps.split,
+		"regexSubmatch": regexps.submatch,
 
 		// Type conversion
-		"toFloat": func(v any) (float64, error) {
-			return toFloat(v)
-		},
-		"toInt": func(v any) (int, error) {
-			return toInt(v)
-		},
+		"toFloat": numbers.toFloat,
+		"toInt":   numbers.toInt,
+		"int64":   numbers.toInt64,
 		"toString": func(v any) string {
 			s, _ := toString(v)
 			return s
@@ -151,7 +249,7 @@ func createHelperFuncs() template.FuncMap {
 		"toInts": func(v []any) ([]int, error) {
 			result := make([]int, len(v))
 			for i, val := range v {
-				intVal, err := toInt(val)
+				intVal, err := numbers.toInt(val)
 				if err != nil {
 					return nil, fmt.Errorf("error converting element %d to int: %w", i, err)
 				}
@@ -162,7 +260,7 @@ func createHelperFuncs() template.FuncMap {
 		"toFloats": func(v []any) ([]float64, error) {
 			result := make([]float64, len(v))
 			for i, val := range v {
-				floatVal, err := toFloat(val)
+				floatVal, err := numbers.toFloat(val)
 				if err != nil {
 					return nil, fmt.Errorf("error converting element %d to float: %w", i, err)
 				}
@@ -172,81 +270,35 @@ func createHelperFuncs() template.FuncMap {
 		},
 
 		// Math operations
-		"add": func(a, b any) (int, error) {
-			aInt, bInt, err := toIntPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aInt + bInt, nil
-		},
-		"sub": func(b, a any) (int, error) {
-			aInt, bInt, err := toIntPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aInt - bInt, nil
-		},
-		"mul": func(a, b any) (int, error) {
-			aInt, bInt, err := toIntPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-
-			return aInt * bInt, nil
-		},
-		"div": func(b, a any) (int, error) {
-			aInt, bInt, err := toIntPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-
-			return aInt / bInt, nil
-		},
-		"mod": func(b, a any) (int, error) {
-
-			aInt, bInt, err := toIntPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aInt % bInt, nil
-		},
+		"add":   numbers.add,
+		"sub":   numbers.sub,
+		"mul":   numbers.mul,
+		"div":   numbers.div,
+		"mod":   numbers.mod,
+		"min":   minNumber,
+		"max":   maxNumber,
+		"abs":   abs,
+		"clamp": clamp,
+		"pow":   numbers.pow,
+		"sqrt":  numbers.sqrt,
+		"sum":   numbers.sum,
+		"avg":   numbers.avg,
 		// Float math operations
-		"addf": func(a, b any) (float64, error) {
-			aFloat, bFloat, err := toFloatPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aFloat + bFloat, nil
-		},
-		"subf": func(b, a any) (float64, error) {
-			aFloat, bFloat, err := toFloatPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aFloat - bFloat, nil
-		},
-		"mulf": func(a, b any) (float64, error) {
-			aFloat, bFloat, err := toFloatPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			return aFloat * bFloat, nil
-		},
-		"divf": func(b, a any) (float64, error) {
-			aFloat, bFloat, err := toFloatPair(a, b)
-			if err != nil {
-				return 0, err
-			}
-			if bFloat == 0 {
-				return 0, fmt.Errorf("division by zero")
-			}
-			return aFloat / bFloat, nil
-		},
+		"addf": numbers.addf,
+		"subf": numbers.subf,
+		"mulf": numbers.mulf,
+		"divf": numbers.divf,
+
+		// Decimal math operations
+		"decimalAdd": decimalAdd,
+		"decimalSub": decimalSub,
+		"decimalMul": decimalMul,
+		"round":      roundNumber,
+		"floor":      floorNumber,
+		"ceil":       ceilNumber,
 
 		// Date/time formatting
-		"now": func() time.Time {
-			return time.Now()
-		},
+		"now": config.currentTime,
 		"parseDate": func(format, dateStr string) (time.Time, error) {
 			t, err := time.Parse(format, dateStr)
 			if err != nil {
@@ -254,9 +306,8 @@ func createHelperFuncs() template.FuncMap {
 			}
 			return t, nil
 		},
-		"formatDate": func(format string, t time.Time) string {
-			return t.Format(format)
-		},
+		"formatDate": formatDate,
+		"parseAny":   parseAny,
 		"timestamp": func(t time.Time) int64 {
 			return t.Unix()
 		},
@@ -269,20 +320,23 @@ func createHelperFuncs() template.FuncMap {
 		"day": func(t time.Time) int {
 			return t.Day()
 		},
+		"inTZ":       inTZ,
+		"utc":        utc,
+		"dateAdd":    dateAdd,
+		"dateSub":    dateSub,
+		"dateDiff":   dateDiff,
+		"duration":   parseDuration,
+		"unixToTime": unixToTime,
+		"weekday":    weekday,
+		"isoWeek":    isoWeek,
+		"startOf":    startOf,
+		"endOf":      endOf,
+		"durationHuman": func(v any) (string, error) {
+			return durationHuman(config.currentTime(), v)
+		},
 
 		// Collection helpers
-		"len": func(v any) int {
-			switch val := v.(type) {
-			case []any:
-				return len(val)
-			case map[string]any:
-				return len(val)
-			case string:
-				return len(val)
-			default:
-				return 0
-			}
-		},
+		"len": lenOf,
 		"first": func(v []any) any {
 			if len(v) > 0 {
 				return v[0]
@@ -301,6 +355,35 @@ func createHelperFuncs() template.FuncMap {
 			}
 			return v[start:end]
 		},
+		"keys":       keys,
+		"sortedKeys": sortedKeys,
+		"values":     values,
+		"entries":    entries,
+		"sortBy":     sortBy,
+		"where":      where,
+		"pluck":      pluck,
+		"groupBy":    groupBy,
+		"uniq":       uniq,
+		"flatten":    flatten,
+		"chunk":      chunk,
+		"reverse":    reverse,
+		"compact":    compact,
+		"indexOf":    indexOf,
+		"has":        has,
+		"append":     appendItem,
+		"prepend":    prependItem,
+		"concat":     concat,
+		"dict":       dict,
+		"list":       list,
+
+		// Data access helpers
+		"get":      get,
+		"hasKey":   hasKey,
+		"set":      set,
+		"unset":    unset,
+		"dig":      dig,
+		"jsonpath": jsonpath,
+		"jq":       runJQ,
 
 		// Conditional helpers
 		"default": func(defaultVal, val any) any {
@@ -309,20 +392,7 @@ func createHelperFuncs() template.FuncMap {
 			}
 			return val
 		},
-		"empty": func(v any) bool {
-			switch val := v.(type) {
-			case nil:
-				return true
-			case string:
-				return val == ""
-			case []any:
-				return len(val) == 0
-			case map[string]any:
-				return len(val) == 0
-			default:
-				return false
-			}
-		},
+		"empty": isEmpty,
 
 		// File path helpers
 		"basename": filepath.Base,
@@ -368,32 +438,99 @@ func createHelperFuncs() template.FuncMap {
 			return string(data), nil
 		},
 
+		"fromJSON": fromJSON,
+
+		// Other serialization formats
+		"toYAML":   toYAML,
+		"fromYAML": fromYAML,
+		"toTOML":   toTOML,
+		"fromTOML": fromTOML,
+		"toINI":    toINI,
+		"toXML":    toXML,
+
 		// hashing functions
-		"sha256": func(s string) string {
-			hash := sha256.Sum256([]byte(s))
-			return fmt.Sprintf("%x", hash)
-		},
+		"sha256":     sha256Sum,
+		"md5":        md5Sum,
+		"sha1":       sha1Sum,
+		"sha384":     sha384Sum,
+		"sha512":     sha512Sum,
+		"hmacSHA256": hmacSHA256,
+		"crc32":      crc32Sum,
+		"fnv":        fnvSum,
+		"bcrypt":     bcryptHash,
+		"htpasswd":   htpasswd,
 
-		"md5": func(s string) string {
-			hash := md5.Sum([]byte(s))
-			return fmt.Sprintf("%x", hash)
-		},
+		// Encoding
+		"base64Encode":          base64Encode,
+		"base64Decode":          base64Decode,
+		"base64URLEncode":       base64URLEncode,
+		"base64URLDecode":       base64URLDecode,
+		"base64RawEncode":       base64RawEncode,
+		"base64RawDecode":       base64RawDecode,
+		"urlSafeBase64":         urlSafeBase64,
+		"urlSafeBase64Decode":   base64URLDecode,
+		"base32Encode":          base32Encode,
+		"base32Decode":          base32Decode,
+		"hexEncode":             hexEncode,
+		"hexDecode":             hexDecode,
+		"gzip":                  gzipCompress,
+		"gunzip":                gunzip,
+		"quotedPrintable":       quotedPrintable,
+		"quotedPrintableDecode": quotedPrintableDecode,
 
-		"sha1": func(s string) string {
-			hash := sha1.Sum([]byte(s))
-			return fmt.Sprintf("%x", hash)
-		},
+		// Escaping
+		"shellQuote":     shellQuote,
+		"sqlQuote":       sqlQuote,
+		"sqlIdent":       sqlIdent,
+		"urlQueryEncode": urlQueryEncode,
+		"urlQueryDecode": urlQueryDecode,
+		"urlPathEncode":  urlPathEncode,
+		"urlPathDecode":  urlPathDecode,
+		"jsonString":     jsonString,
+		"xmlEscape":      xmlEscape,
+		"regexQuote":     regexQuote,
 
-		"base64Encode": func(s string) string {
-			return base64.StdEncoding.EncodeToString([]byte(s))
-		},
+		// URLs
+		"urlParse":    urlParse,
+		"urlJoin":     urlJoin,
+		"urlBuild":    urlBuild,
+		"urlQuerySet": urlQuerySet,
 
-		"base64Decode": func(s string) (string, error) {
-			data, err := base64.StdEncoding.DecodeString(s)
-			if err != nil {
-				return "", fmt.Errorf("failed to decode base64: %w", err)
-			}
-			return string(data), nil
-		},
+		// IP addresses and networks
+		"cidrHost":     cidrHost,
+		"cidrSubnet":   cidrSubnet,
+		"cidrSplit":    cidrSplit,
+		"cidrNetmask":  cidrNetmask,
+		"cidrContains": cidrContains,
+		"ipAdd":        ipAdd,
+		"ipVersion":    ipVersion,
+		"isPrivateIP":  isPrivateIP,
+
+		// Semantic versions
+		"semver":        semverParse,
+		"semverCompare": semverCompare,
+		"semverBump":    semverBump,
+		"semverSort":    semverSort,
+
+		// Random values and identifiers
+		"uuidv4":       random.uuidv4,
+		"uuidv7":       random.uuidv7,
+		"ulid":         random.ulid,
+		"randInt":      random.randInt,
+		"randChoice":   random.randChoice,
+		"shuffle":      random.shuffle,
+		"randAlphaNum": random.randAlphaNum,
+		"randBytes":    random.randBytes,
+
+		// Keys and certificates
+		"genPrivateKey":     random.genPrivateKey,
+		"genSelfSignedCert": random.genSelfSignedCert,
+
+		// Trusted values in HTML mode
+		"safeHTML":     safeHTML,
+		"safeHTMLAttr": safeHTMLAttr,
+		"safeURL":      safeURL,
+		"safeJS":       safeJS,
+		"safeCSS":      safeCSS,
 	}
 }
@@ -11,7 +11,7 @@ import (
 
 // Test string manipulation functions
 func TestStringFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	tests := []struct {
 		name     string
@@ -49,7 +49,7 @@ func TestStringFunctions(t *testing.T) {
 }
 
 func TestJoinFunction(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 	joinFunc := funcs["join"]
 
 	tests := []struct {
@@ -73,7 +73,7 @@ func TestJoinFunction(t *testing.T) {
 
 // Test type conversion functions
 func TestTypeConversionFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("toFloat", func(t *testing.T) {
 		toFloatFunc := funcs["toFloat"]
@@ -192,7 +192,7 @@ func TestTypeConversionFunctions(t *testing.T) {
 
 // Test math operations
 func TestMathFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("integer math", func(t *testing.T) {
 		tests := []struct {
@@ -270,7 +270,7 @@ func TestMathFunctions(t *testing.T) {
 
 // Test date/time functions
 func TestDateTimeFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("now", func(t *testing.T) {
 		nowFunc := funcs["now"]
@@ -339,7 +339,7 @@ func TestDateTimeFunctions(t *testing.T) {
 
 // Test collection helpers
 func TestCollectionHelpers(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("len", func(t *testing.T) {
 		lenFunc := funcs["len"]
@@ -403,7 +403,7 @@ func TestCollectionHelpers(t *testing.T) {
 
 // Test conditional helpers
 func TestConditionalHelpers(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("default", func(t *testing.T) {
 		defaultFunc := funcs["default"]
@@ -455,7 +455,7 @@ func TestConditionalHelpers(t *testing.T) {
 
 // Test file path helpers
 func TestFilePathHelpers(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	tests := []struct {
 		name     string
@@ -490,7 +490,7 @@ func TestFilePathHelpers(t *testing.T) {
 
 // Test JSON functions
 func TestJSONFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	t.Run("toJSON", func(t *testing.T) {
 		toJSONFunc := funcs["toJSON"]
@@ -524,7 +524,7 @@ func TestJSONFunctions(t *testing.T) {
 
 // Test hashing functions
 func TestHashingFunctions(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	tests := []struct {
 		name     string
@@ -569,7 +569,7 @@ func TestHashingFunctions(t *testing.T) {
 
 // Integration tests with Go template engine
 func TestTemplateIntegration(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	tests := []struct {
 		name     string
@@ -703,7 +703,7 @@ func TestTemplateIntegration(t *testing.T) {
 
 // Test error handling in templates
 func TestTemplateErrorHandling(t *testing.T) {
-	funcs := createHelperFuncs()
+	funcs := createHelperFuncs(renderConfig{})
 
 	tests := []struct {
 		name        string
@@ -777,6 +777,8 @@ func callFuncWithError(fn any, args ...any) (any, error) {
 		return f(args[0].(string), args[1].(string)), nil
 	case func(string, string) bool:
 		return f(args[0].(string), args[1].(string)), nil
+	case func(string, any) (string, error):
+		return f(args[0].(string), args[1])
 	case func(string, int) string:
 		return f(args[0].(string), args[1].(int)), nil
 	case func(string, []string) string:
@@ -799,6 +801,10 @@ func callFuncWithError(fn any, args ...any) (any, error) {
 		return f(args[0], args[1])
 	case func(any) int:
 		return f(args[0]), nil
+	case func(...any) (int, error):
+		return f(args...)
+	case func(...any) (float64, error):
+		return f(args...)
 	case func([]any) []string:
 		return f(args[0].([]any)), nil
 	case func([]any) any:
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// isHTMLTemplate reports whether a template file should be rendered in HTML
+// mode based on its name: "page.html", "page.htm" or "page.html.tmpl".
+func isHTMLTemplate(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	switch filepath.Ext(name) {
+	case ".html", ".htm":
+		return true
+	default:
+		return false
+	}
+}
+
+// executeHTMLTemplate executes the template with html/template, which escapes
+// every value according to the context it is written into: HTML text,
+// attributes, URLs, JavaScript or CSS. Strings returned by the helpers are
+// escaped the same way as the data, use the safe* helpers to mark trusted
+// values.
+func executeHTMLTemplate(out io.Writer, templateContent string, data any, config renderConfig) error {
+	tmpl, err := template.New("gotpl").Funcs(createHTMLHelperFuncs(config)).Parse(templateContent)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+
+	if err := tmpl.Execute(out, data); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	return nil
+}
+
+// createHTMLHelperFuncs returns the helper functions for HTML mode. Helpers
+// which already escape their result for a context return it typed, so
+// html/template does not escape it a second time: an encoded query parameter
+// is a URL, JSON (which is written with <, > and & escaped) is JavaScript and
+// escaped XML text and the HTML converted from markdown are HTML.
+func createHTMLHelperFuncs(config renderConfig) template.FuncMap {
+	funcs := template.FuncMap(createHelperFuncs(config))
+	toJSON := funcs["toJSON"].(func(any) (string, error))
+	toPrettyJSON := funcs["toPrettyJSON"].(func(any) (string, error))
+
+	funcs["urlQueryEncode"] = func(v any) template.URL {
+		return template.URL(urlQueryEncode(v))
+	}
+	funcs["jsonString"] = func(v any) (template.JS, error) {
+		s, err := jsonString(v)
+		return template.JS(s), err
+	}
+	funcs["toJSON"] = func(v any) (template.JS, error) {
+		s, err := toJSON(v)
+		return template.JS(s), err
+	}
+	funcs["toPrettyJSON"] = func(v any) (template.JS, error) {
+		s, err := toPrettyJSON(v)
+		return template.JS(s), err
+	}
+	funcs["xmlEscape"] = func(v any) template.HTML {
+		return template.HTML(xmlEscape(v))
+	}
+	funcs["markdown"] = func(args ...any) (template.HTML, error) {
+		s, err := markdown(args...)
+		return template.HTML(s), err
+	}
+	return funcs
+}
+
+// The safe* helpers mark a trusted value, so html/template writes it as is
+// in the matching context. In text mode they return the value unchanged.
+
+func safeHTML(v any) template.HTML {
+	s, _ := toString(v)
+	return template.HTML(s)
+}
+
+func safeHTMLAttr(v any) template.HTMLAttr {
+	s, _ := toString(v)
+	return template.HTMLAttr(s)
+}
+
+func safeURL(v any) template.URL {
+	s, _ := toString(v)
+	return template.URL(s)
+}
+
+func safeJS(v any) template.JS {
+	s, _ := toString(v)
+	return template.JS(s)
+}
+
+func safeCSS(v any) template.CSS {
+	s, _ := toString(v)
+	return template.CSS(s)
+}
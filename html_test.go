@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsHTMLTemplate(t *testing.T) {
+	tests := map[string]bool{
+		"page.html":       true,
+		"PAGE.HTM":        true,
+		"email.html.tmpl": true,
+		"email.html.tpl":  true,
+		"page.tmpl":       false,
+		"config.yaml":     false,
+		"html":            false,
+		"":                false,
+	}
+
+	for name, expected := range tests {
+		if result := isHTMLTemplate(name); result != expected {
+			t.Errorf("isHTMLTemplate(%q): expected %v, got %v", name, expected, result)
+		}
+	}
+}
+
+func TestExecuteHTMLTemplate(t *testing.T) {
+	data := map[string]any{
+		"name":  `<script>alert("x")</script>`,
+		"url":   "javascript:alert(1)",
+		"query": "tom & jerry/2",
+		"user":  map[string]any{"name": "</script><b>"},
+		"trust": "<b>bold</b>",
+		"items": []any{"a", "b"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "text is escaped",
+			template: `<p>{{ .name }}</p>`,
+			expected: `<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>`,
+		},
+		{
+			name:     "helper results are escaped",
+			template: `<p>{{ .name | upper }}</p>`,
+			expected: `<p>&lt;SCRIPT&gt;ALERT(&#34;X&#34;)&lt;/SCRIPT&gt;</p>`,
+		},
+		{
+			name:     "unsafe URL is filtered",
+			template: `<a href="{{ .url }}">x</a>`,
+			expected: `<a href="#ZgotmplZ">x</a>`,
+		},
+		{
+			name:     "safeHTML",
+			template: `<p>{{ safeHTML .trust }}</p>`,
+			expected: `<p><b>bold</b></p>`,
+		},
+		{
+			name:     "safeURL",
+			template: `<a href="{{ safeURL "mailto:a@example.com" }}">x</a>`,
+			expected: `<a href="mailto:a@example.com">x</a>`,
+		},
+		{
+			name:     "safeJS",
+			template: `<script>{{ safeJS "var a = 1;" }}</script>`,
+			expected: `<script>var a = 1;</script>`,
+		},
+		{
+			name:     "safeHTMLAttr",
+			template: `<input {{ safeHTMLAttr "checked" }}>`,
+			expected: `<input checked>`,
+		},
+		{
+			name:     "safeCSS",
+			template: `<p style="{{ safeCSS "color: red" }}">x</p>`,
+			expected: `<p style="color: red">x</p>`,
+		},
+		{
+			name:     "encoded query parameter is not escaped twice",
+			template: `<a href="/search?q={{ urlQueryEncode .query }}">{{ urlQueryEncode .query }}</a>`,
+			expected: `<a href="/search?q=tom&#43;%26&#43;jerry%2F2">tom&#43;%26&#43;jerry%2F2</a>`,
+		},
+		{
+			name:     "JSON in script",
+			template: `<script>var user = {{ toJSON .user }}, name = {{ jsonString .user.name }};</script>`,
+			expected: `<script>var user = {"name":"\u003c/script\u003e\u003cb\u003e"}, name = "\u003c/script\u003e\u003cb\u003e";</script>`,
+		},
+		{
+			name:     "JSON in attribute",
+			template: `<div data-user="{{ toJSON .user }}"></div>`,
+			expected: `<div data-user="{&#34;name&#34;:&#34;\u003c/script\u003e\u003cb\u003e&#34;}"></div>`,
+		},
+		{
+			name:     "escaped XML is not escaped twice",
+			template: `<p>{{ xmlEscape "a < b" }}</p>`,
+			expected: `<p>a &lt; b</p>`,
+		},
+		{
+			name:     "shellQuote result is escaped",
+			template: `<pre>{{ shellQuote "it's <here>" }}</pre>`,
+			expected: `<pre>&#39;it&#39;\&#39;&#39;s &lt;here&gt;&#39;</pre>`,
+		},
+		{
+			name:     "range and collection helpers",
+			template: `<ul>{{ range .items | reverse }}<li>{{ . }}</li>{{ end }}</ul>`,
+			expected: `<ul><li>b</li><li>a</li></ul>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := executeHTMLTemplate(&buf, tt.template, data, renderConfig{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestExecuteHTMLTemplateErrors(t *testing.T) {
+	var buf strings.Builder
+	if err := executeHTMLTemplate(&buf, `{{ .name `, nil, renderConfig{}); err == nil {
+		t.Errorf("expected parse error")
+	}
+	if err := executeHTMLTemplate(&buf, `<a href="{{ .a }}`, map[string]any{"a": "x"}, renderConfig{}); err == nil {
+		t.Errorf("expected error for a template ending in an attribute")
+	}
+}
+
+func TestSafeHelpersInTextMode(t *testing.T) {
+	var buf strings.Builder
+	if err := executeTemplate(&buf, `{{ safeHTML "<b>" }}{{ safeURL "a?b" }}{{ safeJS "x" }}`, nil, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "<b>a?bx" {
+		t.Errorf("expected <b>a?bx, got %s", buf.String())
+	}
+}
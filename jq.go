@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/itchyny/gojq"
+)
+
+func compileJQ(query string) (*gojq.Code, error) {
+	parsed, err := gojq.Parse(query)
+	if err != nil {
+		var parseErr *gojq.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("invalid jq query %q: %w at position %d", query, err, parseErr.Offset)
+		}
+		return nil, fmt.Errorf("invalid jq query %q: %w", query, err)
+	}
+
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq query %q: %w", query, err)
+	}
+	return code, nil
+}
+
+// runJQ runs a jq query on the data. A query producing a single result
+// returns that result, no results return nil and multiple results are
+// returned as a list. Wrap the query in [ ] to always get a list. The numbers
+// of the results are converted like the ones of the data, see plainNumbers.
+func runJQ(query string, data any) (any, error) {
+	code, err := compileJQ(query)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := jqInput(data)
+	if err != nil {
+		return nil, fmt.Errorf("jq query %q: %w", query, err)
+	}
+
+	results := []any{}
+	iter := code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			var haltErr *gojq.HaltError
+			if errors.As(err, &haltErr) && haltErr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("jq query %q failed: %w", query, err)
+		}
+		results = append(results, plainNumbers(v))
+	}
+
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		return results[0], nil
+	default:
+		return results, nil
+	}
+}
+
+// jqInput copies the input of a query into the types gojq accepts, the ones
+// JSON decodes to. The copy also keeps gojq, which normalizes the numbers of
+// its input in place, from changing the template data. Other values, like
+// the []string of split or a time.Time, go through a JSON round trip.
+func jqInput(v any) (any, error) {
+	switch val := v.(type) {
+	case nil, bool, string, float64, int, int64, json.Number, *big.Int:
+		return v, nil
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			converted, err := jqInput(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			converted, err := jqInput(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot use %T as input: %w", v, err)
+		}
+		return decodeJSON(encoded)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunJQ(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			map[string]any{"name": "a", "enabled": true},
+			map[string]any{"name": "b", "enabled": false},
+			map[string]any{"name": "c", "enabled": true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected any
+	}{
+		{"single result", ".items[0].name", "a"},
+		{"multiple results", ".items[] | select(.enabled) | .name", []any{"a", "c"}},
+		{"collected results", "[.items[] | select(.name == \"b\") | .name]", []any{"b"}},
+		{"no results", ".items[] | select(.name == \"x\")", nil},
+		{"identity", ".items | length", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runJQ(tt.query, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalValues(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunJQErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		contains string
+	}{
+		{"parse error", ".items]", "at position 7"},
+		{"unterminated", ".items | select(.a", "at position"},
+		{"unknown function", "nosuchfunc", "nosuchfunc"},
+		{"runtime error", ".items | keys | .[0] | .a", "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runJQ(tt.query, map[string]any{"items": []any{1.0}})
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error to contain %q, got %q", tt.contains, err.Error())
+			}
+		})
+	}
+}
+
+func TestRunJQInputTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		data     any
+		expected any
+	}{
+		{"string slice", "length", []string{"a", "b"}, 2},
+		{"nested string slice", ".tags | join(\",\")", map[string]any{"tags": []string{"a", "b"}}, "a,b"},
+		{"string map", ".a", map[string]string{"a": "x"}, "x"},
+		{"time", ".", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), "2025-01-02T03:04:05Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runJQ(tt.query, tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalValues(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := runJQ(".", map[string]any{"f": func() {}}); err == nil || strings.Contains(err.Error(), "PANIC") {
+			t.Errorf("expected a plain error, got %v", err)
+		}
+	})
+
+	t.Run("template helper output", func(t *testing.T) {
+		var buf strings.Builder
+		if err := executeTemplate(&buf, `{{ jq "length" (split "," "a,b") }}`, nil, renderConfig{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "2" {
+			t.Errorf("expected 2, got %q", buf.String())
+		}
+	})
+
+	t.Run("data is not changed", func(t *testing.T) {
+		data := map[string]any{"n": int64(5)}
+		if _, err := runJQ(".n", data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := data["n"].(int64); !ok {
+			t.Errorf("expected the data to keep its int64, got %T", data["n"])
+		}
+	})
+}
+
+func TestJQTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"users": []any{
+		map[string]any{"name": "John", "admin": true},
+		map[string]any{"name": "Jane", "admin": false},
+	}}
+
+	tpl := `{{ range jq "[.users[] | select(.admin)]" . }}{{ .name }}{{ end }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "John" {
+		t.Errorf("expected %q, got %q", "John", buf.String())
+	}
+}
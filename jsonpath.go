@@ -0,0 +1,552 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep selects nodes from a single node. Recursive steps are applied
+// to the node and all of its descendants.
+type jsonPathStep struct {
+	recursive bool
+	selector  func(node any) []any
+}
+
+// jsonpath evaluates a JSONPath expression against the data and returns the
+// list of matching values. Supported syntax: $ (root), .key and ['key'],
+// wildcards (.* and [*]), recursive descent (..key), indexes ([0], [-1],
+// [0,2]), slices ([1:3], [::2]) and filters ([?(@.price < 10 && @.tag)]).
+// Map values are visited in sorted key order.
+func jsonpath(expr string, data any) ([]any, error) {
+	steps, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: %w", err)
+	}
+
+	nodes := []any{data}
+	for _, step := range steps {
+		next := []any{}
+		for _, node := range nodes {
+			candidates := []any{node}
+			if step.recursive {
+				candidates = descendants(node, nil)
+			}
+			for _, candidate := range candidates {
+				next = append(next, step.selector(candidate)...)
+			}
+		}
+		nodes = next
+	}
+
+	return nodes, nil
+}
+
+func descendants(node any, result []any) []any {
+	result = append(result, node)
+	switch val := node.(type) {
+	case map[string]any:
+		for _, k := range sortedMapKeys(val) {
+			result = descendants(val[k], result)
+		}
+	case []any:
+		for _, item := range val {
+			result = descendants(item, result)
+		}
+	}
+	return result
+}
+
+func children(node any) []any {
+	switch val := node.(type) {
+	case map[string]any:
+		return values(val)
+	case []any:
+		return val
+	default:
+		return nil
+	}
+}
+
+func selectKeys(names ...string) func(any) []any {
+	return func(node any) []any {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		result := []any{}
+		for _, name := range names {
+			if v, ok := m[name]; ok {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+}
+
+func selectWildcard(node any) []any {
+	return children(node)
+}
+
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") {
+		expr = expr[1:]
+	} else if expr != "" && expr[0] != '.' && expr[0] != '[' {
+		expr = "." + expr
+	}
+
+	steps := []jsonPathStep{}
+	for i := 0; i < len(expr); {
+		recursive := false
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			recursive = true
+			i += 2
+		case expr[i] == '.':
+			i++
+		case expr[i] == '[':
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", expr[i], i)
+		}
+
+		if i >= len(expr) {
+			return nil, fmt.Errorf("unexpected end of expression")
+		}
+
+		if expr[i] == '[' {
+			end, err := findBracketEnd(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			selector, err := parseJSONPathBracket(expr[i+1 : end])
+			if err != nil {
+				return nil, fmt.Errorf("%w at position %d", err, i)
+			}
+			steps = append(steps, jsonPathStep{recursive: recursive, selector: selector})
+			i = end + 1
+			continue
+		}
+
+		end := strings.IndexAny(expr[i:], ".[")
+		if end < 0 {
+			end = len(expr) - i
+		}
+		name := expr[i : i+end]
+		if name == "" {
+			return nil, fmt.Errorf("empty key at position %d", i)
+		}
+		if name == "*" {
+			steps = append(steps, jsonPathStep{recursive: recursive, selector: selectWildcard})
+		} else {
+			steps = append(steps, jsonPathStep{recursive: recursive, selector: selectKeys(name)})
+		}
+		i += end
+	}
+
+	return steps, nil
+}
+
+// findBracketEnd returns the position of the bracket closing the one at
+// start, skipping quoted strings and nested brackets.
+func findBracketEnd(expr string, start int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+			if depth == 0 {
+				if c != ']' {
+					return 0, fmt.Errorf("unbalanced parenthesis at position %d", i)
+				}
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("missing ']' for '[' at position %d", start)
+}
+
+func parseJSONPathBracket(content string) (func(any) []any, error) {
+	content = strings.TrimSpace(content)
+
+	switch {
+	case content == "*":
+		return selectWildcard, nil
+	case strings.HasPrefix(content, "?"):
+		filter := strings.TrimSpace(content[1:])
+		if !strings.HasPrefix(filter, "(") || !strings.HasSuffix(filter, ")") {
+			return nil, fmt.Errorf("filter must be enclosed in parentheses")
+		}
+		p := &filterParser{input: filter[1 : len(filter)-1]}
+		expr, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		return func(node any) []any {
+			result := []any{}
+			for _, child := range children(node) {
+				if isTruthy(expr(child)) {
+					result = append(result, child)
+				}
+			}
+			return result
+		}, nil
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\""):
+		var names []string
+		for _, part := range splitOutsideQuotes(content, ',') {
+			name, err := unquote(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		return selectKeys(names...), nil
+	case strings.Contains(content, ":"):
+		return parseJSONPathSlice(content)
+	default:
+		var indexes []int
+		for _, part := range strings.Split(content, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid index '%s'", part)
+			}
+			indexes = append(indexes, index)
+		}
+		return func(node any) []any {
+			list, ok := node.([]any)
+			if !ok {
+				return nil
+			}
+			result := []any{}
+			for _, index := range indexes {
+				if i, ok := listIndex(index, len(list)); ok {
+					result = append(result, list[i])
+				}
+			}
+			return result
+		}, nil
+	}
+}
+
+func parseJSONPathSlice(content string) (func(any) []any, error) {
+	parts := strings.Split(content, ":")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid slice '%s'", content)
+	}
+
+	bounds := make([]*int, 3)
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice '%s'", content)
+		}
+		bounds[i] = &n
+	}
+
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("slice step must be positive in '%s'", content)
+	}
+
+	return func(node any) []any {
+		list, ok := node.([]any)
+		if !ok {
+			return nil
+		}
+
+		normalize := func(b *int, def int) int {
+			if b == nil {
+				return def
+			}
+			n := *b
+			if n < 0 {
+				n += len(list)
+			}
+			return max(0, min(n, len(list)))
+		}
+
+		result := []any{}
+		for i := normalize(bounds[0], 0); i < normalize(bounds[1], len(list)); i += step {
+			result = append(result, list[i])
+		}
+		return result
+	}, nil
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"') || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("invalid quoted string %s", s)
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), nil
+}
+
+func isTruthy(v any) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case missingValue:
+		return false
+	default:
+		return v != nil
+	}
+}
+
+// missingValue marks a path in a filter expression that does not exist, so
+// [?(@.a)] can tell a missing key from a key holding null or false.
+type missingValue struct{}
+
+// filterParser parses JSONPath filter expressions into functions evaluated
+// against the current node (@).
+type filterParser struct {
+	input string
+	pos   int
+}
+
+type filterExpr func(node any) any
+
+func (p *filterParser) parse() (filterExpr, error) {
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected '%s' in filter", p.input[p.pos:])
+	}
+	return expr, nil
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(node any) any {
+			return isTruthy(l(node)) || isTruthy(right(node))
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(node any) any {
+			return isTruthy(l(node)) && isTruthy(right(node))
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	if p.consume("!") {
+		expr, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		return func(node any) any { return !isTruthy(expr(node)) }, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if !p.consume(op) {
+			continue
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return func(node any) any {
+			a, b := left(node), right(node)
+			if _, ok := a.(missingValue); ok {
+				return op == "!="
+			}
+			if _, ok := b.(missingValue); ok {
+				return op == "!="
+			}
+			switch op {
+			case "==":
+				return valuesEqual(a, b)
+			case "!=":
+				return !valuesEqual(a, b)
+			}
+			if a == nil || b == nil {
+				return false
+			}
+			c := compareValues(a, b, "auto")
+			switch op {
+			case "<":
+				return c < 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			default:
+				return c >= 0
+			}
+		}, nil
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseOperand() (filterExpr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	start := p.pos
+	switch c := p.input[p.pos]; {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ')' in filter")
+		}
+		return expr, nil
+	case c == '@':
+		p.pos++
+		for p.pos < len(p.input) && !strings.ContainsRune(" \t=!<>&|)", rune(p.input[p.pos])) {
+			if p.input[p.pos] == '[' {
+				end, err := findBracketEnd(p.input, p.pos)
+				if err != nil {
+					return nil, err
+				}
+				p.pos = end
+			}
+			p.pos++
+		}
+		segments, err := parsePath(p.input[start+1 : p.pos])
+		if err != nil {
+			return nil, err
+		}
+		return func(node any) any {
+			value, ok := getPath(node, segments)
+			if !ok {
+				return missingValue{}
+			}
+			return value
+		}, nil
+	case c == '\'' || c == '"':
+		end := start + 1
+		for end < len(p.input) && p.input[end] != c {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return nil, fmt.Errorf("unterminated string in filter")
+		}
+		p.pos = end + 1
+		s, err := unquote(p.input[start:p.pos])
+		if err != nil {
+			return nil, err
+		}
+		return func(any) any { return s }, nil
+	}
+
+	for p.pos < len(p.input) && !strings.ContainsRune(" \t=!<>&|)", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	literal := p.input[start:p.pos]
+	switch literal {
+	case "true":
+		return func(any) any { return true }, nil
+	case "false":
+		return func(any) any { return false }, nil
+	case "null":
+		return func(any) any { return nil }, nil
+	}
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value '%s' in filter", literal)
+	}
+	return func(any) any { return f }, nil
+}
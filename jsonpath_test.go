@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	data := map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"category": "reference", "author": "Rees", "price": 8.95},
+				map[string]any{"category": "fiction", "author": "Waugh", "price": 12.99},
+				map[string]any{"category": "fiction", "author": "Melville", "price": 8.99, "isbn": "0-553"},
+				map[string]any{"category": "fiction", "author": "Tolkien", "price": 22.99, "isbn": "0-395"},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+		"x-y": map[string]any{"a b": []any{1.0, 2.0}},
+	}
+
+	tests := []struct {
+		expr     string
+		expected []any
+	}{
+		{"$.store.book[*].author", []any{"Rees", "Waugh", "Melville", "Tolkien"}},
+		{"$..author", []any{"Rees", "Waugh", "Melville", "Tolkien"}},
+		{"store.bicycle.color", []any{"red"}},
+		{"$.store.*.color", []any{"red"}},
+		{"$..price", []any{19.95, 8.95, 12.99, 8.99, 22.99}},
+		{"$.store.book[-1].author", []any{"Tolkien"}},
+		{"$.store.book[0,2].author", []any{"Rees", "Melville"}},
+		{"$.store.book[1:3].author", []any{"Waugh", "Melville"}},
+		{"$.store.book[::2].author", []any{"Rees", "Melville"}},
+		{"$.store.book[-2:].author", []any{"Melville", "Tolkien"}},
+		{"$..book[?(@.isbn)].author", []any{"Melville", "Tolkien"}},
+		{"$..book[?(!@.isbn)].author", []any{"Rees", "Waugh"}},
+		{"$..book[?(@.price < 10)].author", []any{"Rees", "Melville"}},
+		{`$..book[?(@.category == 'fiction' && @.price > 20)].author`, []any{"Tolkien"}},
+		{`$..book[?(@.author == "Rees" || (@.price >= 12.99 && @.price <= 13))].author`, []any{"Rees", "Waugh"}},
+		{`$['x-y']['a b'][1]`, []any{2.0}},
+		{"$.missing.key", []any{}},
+		{"$", []any{data}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := jsonpath(tt.expr, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalSlices(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, expr := range []string{
+		"$.store[",
+		"$.store[abc]",
+		"$.store[?@.a]",
+		"$.store[?(@.a ==)]",
+		"$.store[::0]",
+		"$.store..",
+		"$store",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := jsonpath(expr, map[string]any{}); err == nil {
+				t.Errorf("expected error for %s", expr)
+			}
+		})
+	}
+}
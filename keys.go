@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// genPrivateKey generates an "ed25519", "ecdsa" (P-256) or "rsa" (2048 bit)
+// private key in PKCS #8 PEM form. Ed25519 keys are derived from the
+// render's random source, so they are reproducible with --seed, ECDSA and
+// RSA keys always use crypto/rand.
+func (s *randomSource) genPrivateKey(keyType string) (string, error) {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "ed25519":
+		seed := make([]byte, ed25519.SeedSize)
+		if err := s.read("genPrivateKey", seed); err != nil {
+			return "", err
+		}
+		key = ed25519.NewKeyFromSeed(seed)
+	case "ecdsa":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return "", fmt.Errorf("genPrivateKey: unknown key type '%s', expected ed25519, ecdsa or rsa", keyType)
+	}
+	if err != nil {
+		return "", fmt.Errorf("genPrivateKey: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("genPrivateKey: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// parsePrivateKey reads a PEM private key in PKCS #8, PKCS #1 (RSA) or SEC 1
+// (EC) form.
+func parsePrivateKey(name, s string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM private key found", name)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid private key: %w", name, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported private key type %T", name, key)
+	}
+	return signer, nil
+}
+
+// certHosts reads the hosts of a certificate, a single name or a list.
+func certHosts(v any) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, err := toList(v)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(list))
+	for i, item := range list {
+		hosts[i], _ = toString(item)
+	}
+	return hosts, nil
+}
+
+// genSelfSignedCert creates a self-signed server certificate for a private
+// key, in PEM form, valid from now for 365 days or the number of days given
+// first. The hosts are a name or a list of DNS names and IP addresses, the
+// first is the common name: {{ $key | genSelfSignedCert (list "localhost"
+// "127.0.0.1") }}.
+func (s *randomSource) genSelfSignedCert(args ...any) (string, error) {
+	days := 365
+	switch len(args) {
+	case 2:
+	case 3:
+		var err error
+		if days, err = toInt(args[0]); err != nil {
+			return "", fmt.Errorf("genSelfSignedCert: invalid number of days: %w", err)
+		}
+		if days <= 0 {
+			return "", fmt.Errorf("genSelfSignedCert: number of days must be positive, got %d", days)
+		}
+		args = args[1:]
+	default:
+		return "", fmt.Errorf("genSelfSignedCert: expected 2 or 3 arguments, got %d", len(args))
+	}
+
+	hosts, err := certHosts(args[0])
+	if err != nil {
+		return "", fmt.Errorf("genSelfSignedCert: invalid hosts: %w", err)
+	}
+	if len(hosts) == 0 {
+		return "", fmt.Errorf("genSelfSignedCert: at least one host is required")
+	}
+	keyPEM, _ := toString(args[1])
+	key, err := parsePrivateKey("genSelfSignedCert", keyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	serial := make([]byte, 16)
+	if err := s.read("genSelfSignedCert", serial); err != nil {
+		return "", err
+	}
+	serial[0] &= 0x7f
+
+	notBefore := s.now().UTC().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber:          new(big.Int).SetBytes(serial),
+		Subject:               pkix.Name{CommonName: hosts[0]},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, days),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(s.r, template, template, key.Public(), key)
+	if err != nil {
+		return "", fmt.Errorf("genSelfSignedCert: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
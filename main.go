@@ -1,10 +1,15 @@
 package main
 
 import (
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/mattn/go-isatty"
@@ -16,10 +21,26 @@ func showHelp() {
 USAGE:
     %s [OPTIONS] <template-file> [data-file]
     %s [OPTIONS] -t <template-string> [data-file]
+    %s encrypt [--key-file <file>] [value...]
 
 OPTIONS:
     -h, --help              Show this help message
     -t, --template <string> Use template string instead of file
+    -q, --query <jq-query>  Transform the data with a jq query before
+                           executing the template
+    --html                  Escape the output as HTML (html/template), the
+                           default for .html and .htm template files
+    --now <date>            Use this time as the current time, e.g.
+                           2025-01-01T00:00:00Z (default: SOURCE_DATE_EPOCH
+                           if set, else the system clock)
+    --seed <number>         Seed the random helpers, for reproducible output
+    --strict-numbers        Fail instead of truncating or rounding when a
+                           number is converted, e.g. toInt of 3.9
+    --key-file <file>       Passphrase file to decrypt the ENC[...] values of
+                           the data with (default: TPLSUB_KEY_FILE)
+    --sensitive <regex>     Mask the values of matching data keys in error
+                           messages, besides passwords, secrets, tokens,
+                           API keys and decrypted values
 
 ARGUMENTS:
     <template-file>         Path to the Go template file
@@ -27,6 +48,14 @@ ARGUMENTS:
     [data-file]             Optional JSON file containing template data
                            If not provided, data is read from stdin
 
+ENCRYPTION:
+    The encrypt command encrypts each value given as an argument, or else
+    the whole standard input as a single value, and prints the encrypted
+    values for the data file, one per line. Encrypted values are decrypted
+    in memory before the template is executed. Values given as arguments
+    are visible in the process list and the shell history: pipe secrets in
+    on the standard input instead.
+
 DATA INPUT:
     1. From file:    %s template.tmpl data.json
     2. From stdin:   echo '{"name":"John"}' | %s template.tmpl
@@ -51,28 +80,164 @@ EXAMPLES:
     # Hashing and encoding
     echo '{"text":"hello"}' | %s -t 'Hash: {{ sha256 .text }}'
 
+    # Select the data with a jq query
+    echo '{"items":[{"name":"a","enabled":true}]}' | %s -q '[.items[] | select(.enabled)]' -t '{{ len . }}'
+
+    # Render HTML, escaping the data
+    echo '{"name":"<b>John</b>"}' | %s --html -t '<p>Hello {{ .name }}</p>'
+
+    # Encrypt a secret for the data file
+    printf '%%s' "$DB_PASSWORD" | %s encrypt --key-file secret.key
+
 AVAILABLE FUNCTIONS:
-    String:     upper, lower, trim, split, join, contains, replace, repeat
-    Math:       add, sub, mul, div, mod (integers)
+    String:     upper, lower, trim, split, join, contains, replace, repeat,
+                runeLen, width, truncate, padLeft, padRight, center, substr
+    Case:       camelCase, pascalCase, snakeCase, kebabCase, screamingSnake,
+                title, slugify, pluralize, singularize, initials
+    Markdown:   markdown, stripMarkdown
+    Regex:      regexMatch, regexFind, regexFindAll, regexReplace, regexSplit,
+                regexSubmatch
+    Math:       add, sub, mul, div, mod (integers), min, max, abs, pow, sqrt,
+                clamp, sum, avg
     Float:      addf, subf, mulf, divf, toFloat
-    Date:       now, parseDate, formatDate, timestamp, year, month, day
-    Collection: len, first, last, slice, seq
+    Decimal:    decimalAdd, decimalSub, decimalMul, round, floor, ceil
+    Date:       now, parseDate, parseAny, formatDate, timestamp, year, month,
+                day, inTZ, utc, dateAdd, dateSub, dateDiff, duration,
+                durationHuman, unixToTime, weekday, isoWeek, startOf, endOf
+    Collection: len, first, last, slice, seq, keys, sortedKeys, values,
+                entries, sortBy, where, pluck, groupBy, uniq, flatten, chunk,
+                reverse, compact, indexOf, has, append, prepend, concat,
+                dict, list, table
+    Data:       get, hasKey, set, unset, dig, jsonpath, jq
     Condition:  default, empty
     File:       basename, dirname, ext, pathjoin
     System:     env
-    JSON:       toJSON, toPrettyJSON
-    Hash:       md5, sha1, sha256, base64Encode, base64Decode
-    Convert:    toString, toStrings, toInt, toInts, toFloat, toFloats
+    JSON:       toJSON, toPrettyJSON, fromJSON
+    Serialize:  toYAML, fromYAML, toTOML, fromTOML, toINI, toXML
+    Hash:       md5, sha1, sha256, sha384, sha512, hmacSHA256, crc32, fnv,
+                bcrypt, htpasswd
+    Encode:     base64Encode, base64Decode, base64URLEncode, base64URLDecode,
+                base64RawEncode, base64RawDecode, urlSafeBase64,
+                urlSafeBase64Decode, base32Encode, base32Decode, hexEncode,
+                hexDecode, gzip, gunzip, quotedPrintable, quotedPrintableDecode
+    Escape:     shellQuote, sqlQuote, sqlIdent, urlQueryEncode, urlQueryDecode,
+                urlPathEncode, urlPathDecode, jsonString, xmlEscape, regexQuote
+    URL:        urlParse, urlJoin, urlBuild, urlQuerySet
+    Network:    cidrHost, cidrSubnet, cidrSplit, cidrNetmask, cidrContains,
+                ipAdd, ipVersion, isPrivateIP
+    Semver:     semver, semverCompare, semverBump, semverSort
+    Random:     uuidv4, uuidv7, ulid, randInt, randChoice, shuffle,
+                randAlphaNum, randBytes
+    Keys:       genPrivateKey, genSelfSignedCert
+    Convert:    toString, toStrings, toInt, toInts, int64, toFloat, toFloats
+    HTML:       safeHTML, safeHTMLAttr, safeURL, safeJS, safeCSS
 
 For detailed documentation and more examples, visit:
 https://github.com/Ajnasz/tplsub
 
-`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
-func main() {
-	var templateContent, dataFile string
+type options struct {
+	template     string
+	templateFile string
+	dataFile     string
+	query        string
+	html         bool
+	keyFile      string
+	sensitive    string
+	render       renderConfig
+}
+
+// parseArgs parses the command-line arguments (without the program name).
+func parseArgs(args []string) (options, error) {
+	var opts options
+	var positional []string
+	hasTemplate := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-t", "--template", "-q", "--query":
+			if i+1 >= len(args) {
+				if arg == "-t" || arg == "--template" {
+					return opts, fmt.Errorf("template string is missing after %s", arg)
+				}
+				return opts, fmt.Errorf("value is missing after %s", arg)
+			}
+			i++
+			if arg == "-t" || arg == "--template" {
+				opts.template = args[i]
+				hasTemplate = true
+			} else {
+				opts.query = args[i]
+			}
+		case "--html":
+			opts.html = true
+		case "--strict-numbers":
+			opts.render.strictNumbers = true
+		case "--key-file", "--sensitive":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("value is missing after %s", arg)
+			}
+			i++
+			if arg == "--key-file" {
+				opts.keyFile = args[i]
+			} else {
+				if _, err := regexp.Compile(args[i]); err != nil {
+					return opts, fmt.Errorf("invalid --sensitive pattern: %w", err)
+				}
+				opts.sensitive = args[i]
+			}
+		case "--now", "--seed":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("value is missing after %s", arg)
+			}
+			i++
+			if arg == "--now" {
+				now, err := parseNow(args[i])
+				if err != nil {
+					return opts, err
+				}
+				opts.render.now = now
+			} else {
+				seed, err := strconv.ParseInt(args[i], 10, 64)
+				if err != nil {
+					return opts, fmt.Errorf("invalid --seed value '%s': expected an integer", args[i])
+				}
+				opts.render.seed = seed
+				opts.render.seeded = true
+			}
+		default:
+			if len(arg) > 1 && strings.HasPrefix(arg, "-") {
+				return opts, fmt.Errorf("unknown option %s", arg)
+			}
+			positional = append(positional, arg)
+		}
+	}
 
+	if !hasTemplate {
+		if len(positional) == 0 {
+			return opts, errMissingTemplate
+		}
+		opts.templateFile = positional[0]
+		positional = positional[1:]
+	}
+
+	switch len(positional) {
+	case 0:
+	case 1:
+		opts.dataFile = positional[0]
+	default:
+		return opts, fmt.Errorf("unexpected argument %s", positional[1])
+	}
+
+	return opts, nil
+}
+
+var errMissingTemplate = errors.New("template is missing")
+
+func main() {
 	// Check for help flag
 	for _, arg := range os.Args[1:] {
 		if arg == "-h" || arg == "--help" {
@@ -81,76 +246,121 @@ func main() {
 		}
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "encrypt" {
+		if err := runEncrypt(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command-line arguments
-	switch {
-	case len(os.Args) < 2:
-		fmt.Fprintf(os.Stderr, "Usage: %s [-t template_string | template_file] [data_file]\n", os.Args[0])
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, errMissingTemplate) {
+			fmt.Fprintf(os.Stderr, "Usage: %s [-t template_string | template_file] [data_file]\n", os.Args[0])
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(1)
-	case os.Args[1] == "-t" || os.Args[1] == "--template":
-		if len(os.Args) < 3 {
-			fmt.Fprintf(os.Stderr, "Error: template string is missing after %s\n", os.Args[1])
+	}
+
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" && opts.render.now.IsZero() {
+		opts.render.now, err = parseSourceDateEpoch(epoch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		templateContent = os.Args[2]
-		if len(os.Args) > 3 {
-			dataFile = os.Args[3]
-		}
-	default:
-		templateFile := os.Args[1]
-		content, err := os.ReadFile(templateFile)
+	}
+
+	templateContent := opts.template
+	if opts.templateFile != "" {
+		content, err := os.ReadFile(opts.templateFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading template file: %v", err)
 			os.Exit(1)
 		}
 		templateContent = string(content)
-		if len(os.Args) > 2 {
-			dataFile = os.Args[2]
+	}
+
+	data, err := loadData(opts.dataFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v", err)
+		os.Exit(1)
+	}
+
+	keyFile := opts.keyFile
+	if keyFile == "" {
+		keyFile = os.Getenv("TPLSUB_KEY_FILE")
+	}
+	sensitive := newRedactor()
+	data, err = decryptWithKeyFile(data, keyFile, sensitive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error decrypting data: %v", err)
+		os.Exit(1)
+	}
+	sensitiveKeys := []*regexp.Regexp{defaultSensitiveKeys}
+	if opts.sensitive != "" {
+		sensitiveKeys = append(sensitiveKeys, regexp.MustCompile(opts.sensitive))
+	}
+	sensitive.addSensitiveKeys(data, sensitiveKeys...)
+
+	if opts.query != "" {
+		data, err = runJQ(opts.query, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error running query: %s", sensitive.redact(err.Error()))
+			os.Exit(1)
 		}
 	}
 
-	// Load data
+	// Create and execute template
+	execute := executeTemplate
+	if opts.html || isHTMLTemplate(opts.templateFile) {
+		execute = executeHTMLTemplate
+	}
+	if err := execute(os.Stdout, templateContent, data, opts.render); err != nil {
+		fmt.Fprint(os.Stderr, sensitive.redact(err.Error()))
+		os.Exit(1)
+	}
+}
+
+// loadData reads the JSON data from the data file, or from stdin when no
+// data file is given. An empty map is used when stdin is a terminal or empty.
+func loadData(dataFile string) (any, error) {
 	var data any
 	var dataReader io.Reader = os.Stdin
 
 	if dataFile != "" {
 		file, err := os.Open(dataFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening data file: %v", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("Error opening data file: %w", err)
 		}
 		defer file.Close()
 		dataReader = file
 	}
 
 	if dataFile == "" && isatty.IsTerminal(os.Stdin.Fd()) {
-		data = make(map[string]any)
-	} else {
-		decoder := json.NewDecoder(dataReader)
-		if err := decoder.Decode(&data); err != nil {
-			// Allow empty data if stdin is a TTY and no data is piped
-			if dataFile == "" {
-				if err == io.EOF {
-					data = make(map[string]any)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error reading JSON data: %v", err)
-					os.Exit(1)
-				}
-			} else {
-				fmt.Fprintf(os.Stderr, "Error reading JSON data from %s: %v", dataFile, err)
-				os.Exit(1)
+		return make(map[string]any), nil
+	}
+
+	decoder := json.NewDecoder(dataReader)
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		// Allow empty data if stdin is a TTY and no data is piped
+		if dataFile == "" {
+			if err == io.EOF {
+				return make(map[string]any), nil
 			}
+			return nil, fmt.Errorf("Error reading JSON data: %w", err)
 		}
+		return nil, fmt.Errorf("Error reading JSON data from %s: %w", dataFile, err)
 	}
 
-	// Create and execute template
-	if err := executeTemplate(os.Stdout, templateContent, data); err != nil {
-		fmt.Fprintf(os.Stderr, "%v", err)
-		os.Exit(1)
-	}
+	return plainNumbers(data), nil
 }
 
-func executeTemplate(out io.Writer, templateContent string, data any) error {
-	tmpl, err := template.New("gotpl").Funcs(createHelperFuncs()).Parse(templateContent)
+func executeTemplate(out io.Writer, templateContent string, data any, config renderConfig) error {
+	tmpl, err := template.New("gotpl").Funcs(createHelperFuncs(config)).Parse(templateContent)
 	if err != nil {
 		return fmt.Errorf("error parsing template: %w", err)
 	}
@@ -161,3 +371,53 @@ func executeTemplate(out io.Writer, templateContent string, data any) error {
 
 	return nil
 }
+
+// runEncrypt runs the encrypt command: it encrypts the values given as
+// arguments, or the standard input without its trailing line break, and
+// writes them to out, one per line.
+func runEncrypt(args []string, in io.Reader, out io.Writer) error {
+	keyFile := os.Getenv("TPLSUB_KEY_FILE")
+	var values []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--key-file":
+			if i+1 >= len(args) {
+				return fmt.Errorf("value is missing after %s", arg)
+			}
+			i++
+			keyFile = args[i]
+		case arg == "--":
+			values = append(values, args[i+1:]...)
+			i = len(args)
+		case len(arg) > 1 && strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown option %s", arg)
+		default:
+			values = append(values, arg)
+		}
+	}
+	if keyFile == "" {
+		return errors.New("no key file is given (--key-file or TPLSUB_KEY_FILE)")
+	}
+
+	if len(values) == 0 {
+		content, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("failed to read the value: %w", err)
+		}
+		values = []string{strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")}
+	}
+
+	key, err := loadSecretKey(keyFile)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptValues(key, values, rand.Reader)
+	if err != nil {
+		return err
+	}
+	for _, value := range encrypted {
+		fmt.Fprintln(out, value)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecuteTemplate(t *testing.T) {
@@ -292,7 +293,7 @@ func TestExecuteTemplate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			err := executeTemplate(&buf, tt.template, tt.data)
+			err := executeTemplate(&buf, tt.template, tt.data, renderConfig{})
 
 			if tt.expectError {
 				if err == nil {
@@ -316,7 +317,7 @@ func TestExecuteTemplate(t *testing.T) {
 
 func TestExecuteTemplateWithNilData(t *testing.T) {
 	var buf bytes.Buffer
-	err := executeTemplate(&buf, "Hello World", nil)
+	err := executeTemplate(&buf, "Hello World", nil, renderConfig{})
 	if err != nil {
 		t.Errorf("unexpected error with nil data: %v", err)
 	}
@@ -330,7 +331,7 @@ func TestExecuteTemplateWithNilData(t *testing.T) {
 
 func TestExecuteTemplateWithEmptyTemplate(t *testing.T) {
 	var buf bytes.Buffer
-	err := executeTemplate(&buf, "", map[string]any{})
+	err := executeTemplate(&buf, "", map[string]any{}, renderConfig{})
 	if err != nil {
 		t.Errorf("unexpected error with empty template: %v", err)
 	}
@@ -346,7 +347,7 @@ func TestExecuteTemplateSequenceFunction(t *testing.T) {
 	template := "Sequence: {{ range seq 1 5 }}{{ . }} {{ end }}"
 	data := map[string]any{}
 
-	err := executeTemplate(&buf, template, data)
+	err := executeTemplate(&buf, template, data, renderConfig{})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 		return
@@ -358,3 +359,131 @@ func TestExecuteTemplateSequenceFunction(t *testing.T) {
 		t.Errorf("expected %q, got %q", expected, result)
 	}
 }
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected options
+		hasError bool
+	}{
+		{
+			name:     "template file",
+			args:     []string{"tpl.tmpl"},
+			expected: options{templateFile: "tpl.tmpl"},
+		},
+		{
+			name:     "template file and data file",
+			args:     []string{"tpl.tmpl", "data.json"},
+			expected: options{templateFile: "tpl.tmpl", dataFile: "data.json"},
+		},
+		{
+			name:     "template string and data file",
+			args:     []string{"-t", "{{ . }}", "data.json"},
+			expected: options{template: "{{ . }}", dataFile: "data.json"},
+		},
+		{
+			name:     "query before template",
+			args:     []string{"--query", ".items", "--template", "{{ . }}"},
+			expected: options{template: "{{ . }}", query: ".items"},
+		},
+		{
+			name:     "query after positional arguments",
+			args:     []string{"tpl.tmpl", "data.json", "-q", ".a"},
+			expected: options{templateFile: "tpl.tmpl", dataFile: "data.json", query: ".a"},
+		},
+		{
+			name:     "html mode",
+			args:     []string{"--html", "-t", "<p>{{ . }}</p>"},
+			expected: options{template: "<p>{{ . }}</p>", html: true},
+		},
+		{
+			name:     "fixed clock and seed",
+			args:     []string{"--now", "2025-01-01T00:00:00Z", "--seed", "42", "-t", "{{ now }}"},
+			expected: options{template: "{{ now }}", render: renderConfig{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), seed: 42, seeded: true}},
+		},
+		{
+			name:     "zero seed",
+			args:     []string{"--seed", "0", "tpl.tmpl"},
+			expected: options{templateFile: "tpl.tmpl", render: renderConfig{seeded: true}},
+		},
+		{
+			name:     "strict numbers",
+			args:     []string{"--strict-numbers", "tpl.tmpl"},
+			expected: options{templateFile: "tpl.tmpl", render: renderConfig{strictNumbers: true}},
+		},
+		{
+			name:     "key file",
+			args:     []string{"tpl.tmpl", "--key-file", "secret.key"},
+			expected: options{templateFile: "tpl.tmpl", keyFile: "secret.key"},
+		},
+		{name: "no arguments", args: []string{}, hasError: true},
+		{name: "missing template string", args: []string{"-t"}, hasError: true},
+		{name: "missing query", args: []string{"tpl.tmpl", "--query"}, hasError: true},
+		{name: "unknown option", args: []string{"--unknown", "tpl.tmpl"}, hasError: true},
+		{name: "invalid now", args: []string{"--now", "soon", "tpl.tmpl"}, hasError: true},
+		{name: "missing now", args: []string{"tpl.tmpl", "--now"}, hasError: true},
+		{
+			name:     "sensitive keys",
+			args:     []string{"--sensitive", "^pin$", "tpl.tmpl"},
+			expected: options{templateFile: "tpl.tmpl", sensitive: "^pin$"},
+		},
+		{name: "invalid sensitive pattern", args: []string{"--sensitive", "(", "tpl.tmpl"}, hasError: true},
+		{name: "missing key file", args: []string{"tpl.tmpl", "--key-file"}, hasError: true},
+		{name: "invalid seed", args: []string{"--seed", "1.5", "tpl.tmpl"}, hasError: true},
+		{name: "too many arguments", args: []string{"tpl.tmpl", "a.json", "b.json"}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseArgs(tt.args)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunEncrypt(t *testing.T) {
+	keyFile := writeKeyFile(t, "correct horse")
+	key, _ := loadSecretKey(keyFile)
+
+	var out bytes.Buffer
+	if err := runEncrypt([]string{"--key-file", keyFile, "first", "--", "-second"}, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 values, got %q", out.String())
+	}
+	for i, expected := range []string{"first", "-second"} {
+		if decrypted, err := key.decrypt(lines[i]); err != nil || decrypted != expected {
+			t.Errorf("expected %s, got %q (%v)", expected, decrypted, err)
+		}
+	}
+
+	out.Reset()
+	t.Setenv("TPLSUB_KEY_FILE", keyFile)
+	if err := runEncrypt(nil, strings.NewReader("from stdin\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decrypted, _ := key.decrypt(strings.TrimSpace(out.String())); decrypted != "from stdin" {
+		t.Errorf("expected the standard input without the line break, got %q", decrypted)
+	}
+
+	t.Setenv("TPLSUB_KEY_FILE", "")
+	for _, args := range [][]string{{"value"}, {"--key-file"}, {"--key-file", keyFile, "--unknown"}} {
+		if err := runEncrypt(args, strings.NewReader(""), &out); err == nil {
+			t.Errorf("runEncrypt(%v): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	// markdownSafe omits raw HTML and links with dangerous URLs like
+	// javascript:, markdownUnsafe keeps them.
+	markdownSafe   = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	markdownUnsafe = goldmark.New(goldmark.WithExtensions(extension.GFM), goldmark.WithRendererOptions(html.WithUnsafe()))
+
+	// markdownPolicy allows the elements user generated content may use,
+	// plus what the markdown renderer writes: task list checkboxes, code
+	// block languages and table cell alignment.
+	markdownPolicy = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+		p.AllowAttrs("checked", "disabled").OnElements("input")
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+		p.AllowStyles("text-align").MatchingEnum("left", "right", "center").OnElements("th", "td")
+		return p
+	}()
+)
+
+// markdown converts CommonMark with the GitHub extensions (tables, task
+// lists, strikethrough and autolinks) to HTML. It is called as (s) or with
+// an option before the text: by default raw HTML in the text is omitted,
+// "sanitize" keeps the raw HTML allowed in user generated content, "unsafe"
+// keeps all of it.
+func markdown(args ...any) (string, error) {
+	var mode string
+	var s string
+	switch len(args) {
+	case 1:
+		mode = "safe"
+		s, _ = toString(args[0])
+	case 2:
+		mode, _ = toString(args[0])
+		s, _ = toString(args[1])
+	default:
+		return "", fmt.Errorf("markdown: expected 1 or 2 arguments, got %d", len(args))
+	}
+
+	md := markdownSafe
+	switch mode {
+	case "safe":
+	case "sanitize", "unsafe":
+		md = markdownUnsafe
+	default:
+		return "", fmt.Errorf("markdown: unknown option '%s'", mode)
+	}
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(s), &buf); err != nil {
+		return "", fmt.Errorf("failed to convert markdown: %w", err)
+	}
+	if mode == "sanitize" {
+		return markdownPolicy.Sanitize(buf.String()), nil
+	}
+	return buf.String(), nil
+}
+
+// stripMarkdown converts markdown to plain text: the formatting and raw HTML
+// are removed, links and images are replaced with their text. Blocks are
+// separated by empty lines, list items and table rows are written on separate
+// lines, table cells are separated by tabs.
+func stripMarkdown(s string) string {
+	source := []byte(s)
+	doc := markdownSafe.Parser().Parse(text.NewReader(source))
+	return strings.Join(plainBlocks(doc, source), "\n\n")
+}
+
+func plainBlocks(n ast.Node, source []byte) []string {
+	var blocks []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if block := plainBlock(c, source); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func plainBlock(n ast.Node, source []byte) string {
+	switch node := n.(type) {
+	case *ast.HTMLBlock, *ast.ThematicBreak:
+		return ""
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		var b strings.Builder
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			b.Write(segment.Value(source))
+		}
+		return strings.TrimRight(b.String(), "\n")
+	case *ast.List:
+		var items []string
+		for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+			if s := strings.Join(plainBlocks(item, source), "\n"); s != "" {
+				items = append(items, s)
+			}
+		}
+		return strings.Join(items, "\n")
+	case *east.Table:
+		var rows []string
+		for row := node.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, plainInline(cell, source))
+			}
+			rows = append(rows, strings.Join(cells, "\t"))
+		}
+		return strings.Join(rows, "\n")
+	case *ast.Blockquote:
+		return strings.Join(plainBlocks(node, source), "\n\n")
+	default:
+		return strings.TrimSpace(plainInline(node, source))
+	}
+}
+
+func plainInline(n ast.Node, source []byte) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch node := c.(type) {
+		case *ast.Text:
+			value := node.Value(source)
+			if !node.IsRaw() {
+				value = util.ResolveEntityNames(util.ResolveNumericReferences(util.UnescapePunctuations(value)))
+			}
+			b.Write(value)
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte('\n')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.Label(source))
+		case *ast.RawHTML, *east.TaskCheckBox:
+		default:
+			b.WriteString(plainInline(node, source))
+		}
+	}
+	return b.String()
+}
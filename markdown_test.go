@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []any
+		expected string
+	}{
+		{
+			name:     "inline formatting",
+			args:     []any{"Some *emph*, **bold** and `code`"},
+			expected: "<p>Some <em>emph</em>, <strong>bold</strong> and <code>code</code></p>\n",
+		},
+		{
+			name:     "table",
+			args:     []any{"| Name | Age |\n|---|--:|\n| Ádám | 30 |"},
+			expected: "<table>\n<thead>\n<tr>\n<th>Name</th>\n<th style=\"text-align:right\">Age</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>Ádám</td>\n<td style=\"text-align:right\">30</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "task list",
+			args:     []any{"- [x] done\n- [ ] todo"},
+			expected: "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n<li><input disabled=\"\" type=\"checkbox\"> todo</li>\n</ul>\n",
+		},
+		{
+			name:     "strikethrough and autolink",
+			args:     []any{"~~old~~ https://example.com"},
+			expected: "<p><del>old</del> <a href=\"https://example.com\">https://example.com</a></p>\n",
+		},
+		{
+			name:     "raw HTML is omitted by default",
+			args:     []any{"<script>alert(1)</script>\n\n[x](javascript:alert(1))"},
+			expected: "<!-- raw HTML omitted -->\n<p><a href=\"\">x</a></p>\n",
+		},
+		{
+			name:     "sanitize",
+			args:     []any{"sanitize", "<div onclick=\"x()\">hi <script>alert(1)</script></div>\n\n[x](javascript:alert(1))"},
+			expected: "<div>hi </div>\n<p>x</p>\n",
+		},
+		{
+			name:     "sanitize keeps markdown output",
+			args:     []any{"sanitize", "- [x] done\n\n```go\nx := 1\n```"},
+			expected: "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>\n<pre><code class=\"language-go\">x := 1\n</code></pre>\n",
+		},
+		{
+			name:     "unsafe",
+			args:     []any{"unsafe", "<b onclick=\"x()\">hi</b>"},
+			expected: "<p><b onclick=\"x()\">hi</b></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := markdown(tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := markdown("trusted", "x"); err == nil {
+		t.Errorf("expected error for unknown option")
+	}
+	if _, err := markdown(); err == nil {
+		t.Errorf("expected error without arguments")
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"formatting", "# Title &amp; more\n\nSome *emph* and **bold** `code`.", "Title & more\n\nSome emph and bold code."},
+		{"escapes", `\*not emph\*`, "*not emph*"},
+		{"links and images", "[link](https://example.com) ![alt text](a.png) <https://auto.link>", "link alt text https://auto.link"},
+		{"lists", "- [x] done\n- [ ] todo\n  - nested\n\n1. first\n2. second", "done\ntodo\nnested\n\nfirst\nsecond"},
+		{"table", "| Name | Age |\n|---|--:|\n| Ádám | 30 |", "Name\tAge\nÁdám\t30"},
+		{"code block", "```\nline 1\nline 2\n```", "line 1\nline 2"},
+		{"raw HTML", "<div>block</div>\n\ntext <b>bold</b>\n\n---", "text bold"},
+		{"quote", "> quoted\n>\n> text", "quoted\n\ntext"},
+		{"line breaks", "first\nsecond", "first\nsecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := stripMarkdown(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMarkdownTemplate(t *testing.T) {
+	data := map[string]any{"body": "Hello **<John>**"}
+
+	var text strings.Builder
+	if err := executeTemplate(&text, `{{ markdown .body }}`, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "<p>Hello <strong><!-- raw HTML omitted --></strong></p>\n"; text.String() != expected {
+		t.Errorf("expected %q, got %q", expected, text.String())
+	}
+
+	var html strings.Builder
+	data = map[string]any{"body": "Hello **John** & <i>co</i>"}
+	if err := executeHTMLTemplate(&html, `<div>{{ markdown "sanitize" .body }}</div><p>{{ .body }}</p>`, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "<div><p>Hello <strong>John</strong> &amp; <i>co</i></p>\n</div><p>Hello **John** &amp; &lt;i&gt;co&lt;/i&gt;</p>"
+	if html.String() != expected {
+		t.Errorf("expected %q, got %q", expected, html.String())
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// The math helpers take the piped value last: {{ 7 | sub 3 }} is 4 and
+// {{ 3 | pow 2 }} is 9. add, mul, addf and mulf take two or more numbers,
+// but sub, div, mod, subf and divf stay at exactly two: their operands do
+// not commute, so with more of them the piped value would have to be taken
+// apart from the others; chaining {{ 10 | sub 3 | sub 2 }} says it plainly.
+// The integer helpers report overflows and arguments outside the range of
+// int instead of wrapping around, and division by zero instead of panicking.
+
+var (
+	errIntOverflow    = errors.New("integer overflow")
+	errDivisionByZero = errors.New("division by zero")
+)
+
+func addInt(a, b int) (int, bool) {
+	s := a + b
+	return s, (s > a) == (b > 0)
+}
+
+func subInt(a, b int) (int, bool) {
+	d := a - b
+	return d, (d < a) == (b > 0)
+}
+
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return p, false
+	}
+	return p, p/b == a
+}
+
+// ints converts the arguments of a variadic integer helper.
+func (c numberConverter) ints(name string, args []any) ([]int, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s: expected at least 2 arguments, got %d", name, len(args))
+	}
+	result := make([]int, len(args))
+	for i, arg := range args {
+		n, err := c.toInt(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: cannot convert argument %d to int: %w", name, i+1, err)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+func (c numberConverter) floats(name string, args []any) ([]float64, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s: expected at least 2 arguments, got %d", name, len(args))
+	}
+	result := make([]float64, len(args))
+	for i, arg := range args {
+		f, err := c.toFloat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: cannot convert argument %d to float: %w", name, i+1, err)
+		}
+		result[i] = f
+	}
+	return result, nil
+}
+
+// add adds two or more integers: {{ add 1 2 3 }}.
+func (c numberConverter) add(args ...any) (int, error) {
+	values, err := c.ints("add", args)
+	if err != nil {
+		return 0, err
+	}
+	return sumInts("add", values)
+}
+
+func sumInts(name string, values []int) (int, error) {
+	total := 0
+	for _, n := range values {
+		var ok bool
+		if total, ok = addInt(total, n); !ok {
+			return 0, fmt.Errorf("%s: %w", name, errIntOverflow)
+		}
+	}
+	return total, nil
+}
+
+func (c numberConverter) sub(b, a any) (int, error) {
+	aInt, bInt, err := c.intPair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	d, ok := subInt(aInt, bInt)
+	if !ok {
+		return 0, fmt.Errorf("sub: %w", errIntOverflow)
+	}
+	return d, nil
+}
+
+// mul multiplies two or more integers.
+func (c numberConverter) mul(args ...any) (int, error) {
+	values, err := c.ints("mul", args)
+	if err != nil {
+		return 0, err
+	}
+	product := 1
+	for _, n := range values {
+		var ok bool
+		if product, ok = mulInt(product, n); !ok {
+			return 0, fmt.Errorf("mul: %w", errIntOverflow)
+		}
+	}
+	return product, nil
+}
+
+func (c numberConverter) div(b, a any) (int, error) {
+	aInt, bInt, err := c.intPair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if bInt == 0 {
+		return 0, fmt.Errorf("div: %w", errDivisionByZero)
+	}
+	if aInt == math.MinInt && bInt == -1 {
+		return 0, fmt.Errorf("div: %w", errIntOverflow)
+	}
+	return aInt / bInt, nil
+}
+
+func (c numberConverter) mod(b, a any) (int, error) {
+	aInt, bInt, err := c.intPair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if bInt == 0 {
+		return 0, fmt.Errorf("mod: %w", errDivisionByZero)
+	}
+	return aInt % bInt, nil
+}
+
+// addf adds two or more floats.
+func (c numberConverter) addf(args ...any) (float64, error) {
+	values, err := c.floats("addf", args)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, f := range values {
+		total += f
+	}
+	return total, nil
+}
+
+func (c numberConverter) subf(b, a any) (float64, error) {
+	aFloat, bFloat, err := c.floatPair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return aFloat - bFloat, nil
+}
+
+// mulf multiplies two or more floats.
+func (c numberConverter) mulf(args ...any) (float64, error) {
+	values, err := c.floats("mulf", args)
+	if err != nil {
+		return 0, err
+	}
+	product := 1.0
+	for _, f := range values {
+		product *= f
+	}
+	return product, nil
+}
+
+func (c numberConverter) divf(b, a any) (float64, error) {
+	aFloat, bFloat, err := c.floatPair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if bFloat == 0 {
+		return 0, errDivisionByZero
+	}
+	return aFloat / bFloat, nil
+}
+
+// numberArgs returns the numbers of min and max: the arguments, or the
+// items of a single list argument.
+func numberArgs(name string, args []any) ([]any, []*big.Rat, error) {
+	if len(args) == 1 {
+		if list, err := toList(args[0]); err == nil {
+			args = list
+		}
+	}
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("%s: expected at least 1 number", name)
+	}
+	rats := make([]*big.Rat, len(args))
+	for i, arg := range args {
+		r, err := toRat(arg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		rats[i] = r
+	}
+	return args, rats, nil
+}
+
+// minNumber returns the smallest of the numbers, or of the items of a
+// list, as it is given: {{ min 3 1 2 }}, {{ min .prices }}.
+func minNumber(args ...any) (any, error) {
+	values, rats, err := numberArgs("min", args)
+	if err != nil {
+		return nil, err
+	}
+	found := 0
+	for i := range rats {
+		if rats[i].Cmp(rats[found]) < 0 {
+			found = i
+		}
+	}
+	return values[found], nil
+}
+
+// maxNumber returns the largest of the numbers, or of the items of a list.
+func maxNumber(args ...any) (any, error) {
+	values, rats, err := numberArgs("max", args)
+	if err != nil {
+		return nil, err
+	}
+	found := 0
+	for i := range rats {
+		if rats[i].Cmp(rats[found]) > 0 {
+			found = i
+		}
+	}
+	return values[found], nil
+}
+
+// clamp limits a number to the range from lo to hi: {{ .percent | clamp 0
+// 100 }}. The number is returned as it is given when it is in the range.
+func clamp(lo, hi, v any) (any, error) {
+	values, rats, err := numberArgs("clamp", []any{lo, hi, v})
+	if err != nil {
+		return nil, err
+	}
+	if rats[0].Cmp(rats[1]) > 0 {
+		return nil, fmt.Errorf("clamp: lower bound %v is greater than upper bound %v", lo, hi)
+	}
+	switch {
+	case rats[2].Cmp(rats[0]) < 0:
+		return values[0], nil
+	case rats[2].Cmp(rats[1]) > 0:
+		return values[1], nil
+	default:
+		return values[2], nil
+	}
+}
+
+// abs returns the absolute value of a number, of the same type for ints
+// and floats, and as an exact decimal for other numbers.
+func abs(v any) (any, error) {
+	switch n := v.(type) {
+	case int:
+		if n == math.MinInt {
+			return nil, fmt.Errorf("abs: %w", errIntOverflow)
+		}
+		return max(n, -n), nil
+	case int64:
+		if n == math.MinInt64 {
+			return nil, fmt.Errorf("abs: %w", errIntOverflow)
+		}
+		return max(n, -n), nil
+	case float64:
+		return math.Abs(n), nil
+	}
+	r, err := toRat(v)
+	if err != nil {
+		return nil, fmt.Errorf("abs: %w", err)
+	}
+	return formatDecimal(new(big.Rat).Abs(r), scaleOf(v, r)), nil
+}
+
+// exactInt returns the value of an integer: an int, an int64 or a
+// json.Number written without a fraction or exponent.
+func exactInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), n >= math.MinInt && n <= math.MaxInt
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 0)
+		return int(i), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// pow raises a number to a power, called as (exp, base): {{ 3 | pow 2 }}
+// is 9. Integers raised to non-negative integer powers give an int, checked
+// for overflow, other numbers a float.
+func (c numberConverter) pow(exp, base any) (any, error) {
+	if b, ok := exactInt(base); ok {
+		if e, ok := exactInt(exp); ok && e >= 0 {
+			// Exponentiation by squaring; b is only squared while a
+			// higher bit of e needs it, so its overflow is the result's.
+			result := 1
+			for ; e > 0; e >>= 1 {
+				if e&1 == 1 {
+					if result, ok = mulInt(result, b); !ok {
+						return nil, fmt.Errorf("pow: %w", errIntOverflow)
+					}
+				}
+				if e > 1 {
+					if b, ok = mulInt(b, b); !ok {
+						return nil, fmt.Errorf("pow: %w", errIntOverflow)
+					}
+				}
+			}
+			return result, nil
+		}
+	}
+	b, e, err := c.floatPair(base, exp)
+	if err != nil {
+		return nil, fmt.Errorf("pow: %w", err)
+	}
+	result := math.Pow(b, e)
+	if math.IsNaN(result) {
+		return nil, fmt.Errorf("pow: %v to the power of %v is not a real number", base, exp)
+	}
+	if math.IsInf(result, 0) {
+		return nil, fmt.Errorf("pow: %v to the power of %v is out of range", base, exp)
+	}
+	return result, nil
+}
+
+// sqrt returns the square root of a number as a float.
+func (c numberConverter) sqrt(v any) (float64, error) {
+	f, err := c.toFloat(v)
+	if err != nil {
+		return 0, fmt.Errorf("sqrt: %w", err)
+	}
+	if f < 0 {
+		return 0, fmt.Errorf("sqrt: cannot take the square root of negative number %v", v)
+	}
+	return math.Sqrt(f), nil
+}
+
+// sum adds the numbers of a list: an int when all of them are integers,
+// else a float. The sum of an empty list is 0.
+func (c numberConverter) sum(v any) (any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("sum: %w", err)
+	}
+	ints := make([]int, 0, len(list))
+	for _, item := range list {
+		n, ok := exactInt(item)
+		if !ok {
+			break
+		}
+		ints = append(ints, n)
+	}
+	if len(ints) == len(list) {
+		return sumInts("sum", ints)
+	}
+	return c.sumFloats("sum", list)
+}
+
+func (c numberConverter) sumFloats(name string, list []any) (float64, error) {
+	total := 0.0
+	for i, item := range list {
+		f, err := c.toFloat(item)
+		if err != nil {
+			return 0, fmt.Errorf("%s: cannot convert item %d to float: %w", name, i, err)
+		}
+		total += f
+	}
+	return total, nil
+}
+
+// avg returns the average of the numbers of a list, as a float.
+func (c numberConverter) avg(v any) (float64, error) {
+	list, err := toList(v)
+	if err != nil {
+		return 0, fmt.Errorf("avg: %w", err)
+	}
+	if len(list) == 0 {
+		return 0, errors.New("avg: empty list")
+	}
+	total, err := c.sumFloats("avg", list)
+	if err != nil {
+		return 0, err
+	}
+	return total / float64(len(list)), nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIntArithmetic(t *testing.T) {
+	c := numberConverter{}
+	tests := []struct {
+		name     string
+		fn       func() (int, error)
+		expected int
+		err      error
+	}{
+		{"add variadic", func() (int, error) { return c.add(1, 2, 3) }, 6, nil},
+		{"add json.Number", func() (int, error) { return c.add(json.Number("40"), "2") }, 42, nil},
+		{"add overflow", func() (int, error) { return c.add(math.MaxInt, 1) }, 0, errIntOverflow},
+		{"add negative overflow", func() (int, error) { return c.add(math.MinInt, -1) }, 0, errIntOverflow},
+		{"sub", func() (int, error) { return c.sub(3, 10) }, 7, nil},
+		{"sub overflow", func() (int, error) { return c.sub(1, math.MinInt) }, 0, errIntOverflow},
+		{"mul variadic", func() (int, error) { return c.mul(2, 3, 4) }, 24, nil},
+		{"mul negative", func() (int, error) { return c.mul(-2, 3) }, -6, nil},
+		{"mul overflow", func() (int, error) { return c.mul(math.MaxInt/2+1, 2) }, 0, errIntOverflow},
+		{"mul min int", func() (int, error) { return c.mul(math.MinInt, -1) }, 0, errIntOverflow},
+		{"div", func() (int, error) { return c.div(3, 10) }, 3, nil},
+		{"div by zero", func() (int, error) { return c.div(0, 10) }, 0, errDivisionByZero},
+		{"div overflow", func() (int, error) { return c.div(-1, math.MinInt) }, 0, errIntOverflow},
+		{"mod", func() (int, error) { return c.mod(3, 10) }, 1, nil},
+		{"mod by zero", func() (int, error) { return c.mod(0, 10) }, 0, errDivisionByZero},
+		{"mod min int", func() (int, error) { return c.mod(-1, math.MinInt) }, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("out of range arguments", func(t *testing.T) {
+		for _, big := range []any{1e20, json.Number("100000000000000000000"), json.Number("-1e20")} {
+			if result, err := c.add(big, 1); err == nil {
+				t.Errorf("add(%v, 1): expected error, got %d", big, result)
+			}
+			if result, err := c.mul(big, 1); err == nil {
+				t.Errorf("mul(%v, 1): expected error, got %d", big, result)
+			}
+			if result, err := c.sub(1, big); err == nil {
+				t.Errorf("sub(1, %v): expected error, got %d", big, result)
+			}
+		}
+
+		var out strings.Builder
+		data := map[string]any{"big": json.Number("100000000000000000000")}
+		if err := executeTemplate(&out, `{{ add .big 1 }}`, data, renderConfig{}); err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("expected out of range error, got %v (%q)", err, out.String())
+		}
+	})
+
+	t.Run("too few arguments", func(t *testing.T) {
+		if _, err := c.add(1); err == nil {
+			t.Errorf("expected error for a single argument")
+		}
+		if _, err := c.mulf(1.5); err == nil {
+			t.Errorf("expected error for a single argument")
+		}
+	})
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	c := numberConverter{}
+	if result, err := c.addf(0.5, 1, "1.5"); err != nil || result != 3 {
+		t.Errorf("addf: expected 3, got %v (%v)", result, err)
+	}
+	if result, err := c.mulf(0.5, 2, 3); err != nil || result != 3 {
+		t.Errorf("mulf: expected 3, got %v (%v)", result, err)
+	}
+	if _, err := c.divf(0, 1); !errors.Is(err, errDivisionByZero) {
+		t.Errorf("divf: expected division by zero, got %v", err)
+	}
+}
+
+func TestMinMaxClamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (any, error)
+		expected any
+	}{
+		{"min", func() (any, error) { return minNumber(3, 1.5, 2) }, 1.5},
+		{"min list", func() (any, error) { return minNumber([]any{json.Number("3"), json.Number("-1")}) }, json.Number("-1")},
+		{"min single", func() (any, error) { return minNumber(5) }, 5},
+		{"max", func() (any, error) { return maxNumber(3, 1, "7") }, "7"},
+		{"max big", func() (any, error) {
+			return maxNumber(json.Number("9007199254740992"), json.Number("9007199254740993"))
+		}, json.Number("9007199254740993")},
+		{"clamp below", func() (any, error) { return clamp(0, 100, -5) }, 0},
+		{"clamp above", func() (any, error) { return clamp(0, 100, json.Number("150")) }, 100},
+		{"clamp inside", func() (any, error) { return clamp(0, 100, 42.5) }, 42.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, result, result)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := minNumber(); err == nil {
+			t.Errorf("expected error without numbers")
+		}
+		if _, err := maxNumber([]any{}); err == nil {
+			t.Errorf("expected error for an empty list")
+		}
+		if _, err := minNumber(1, "abc"); err == nil || !strings.HasPrefix(err.Error(), "min:") {
+			t.Errorf("expected min error, got %v", err)
+		}
+		if _, err := clamp(10, 1, 5); err == nil {
+			t.Errorf("expected error for lower bound above upper bound")
+		}
+	})
+}
+
+func TestAbsPowSqrt(t *testing.T) {
+	c := numberConverter{}
+	tests := []struct {
+		name     string
+		fn       func() (any, error)
+		expected any
+	}{
+		{"abs int", func() (any, error) { return abs(-3) }, 3},
+		{"abs float", func() (any, error) { return abs(-2.5) }, 2.5},
+		{"abs json.Number", func() (any, error) { return abs(json.Number("-0.10")) }, json.Number("0.10")},
+		{"pow int", func() (any, error) { return c.pow(10, 2) }, 1024},
+		{"pow zero", func() (any, error) { return c.pow(0, 7) }, 1},
+		{"pow negative base", func() (any, error) { return c.pow(3, -2) }, -8},
+		{"pow json.Number", func() (any, error) { return c.pow(json.Number("2"), json.Number("12")) }, 144},
+		{"pow one large exponent", func() (any, error) { return c.pow(1000000000000, 1) }, 1},
+		{"pow float", func() (any, error) { return c.pow(0.5, 9) }, 3.0},
+		{"pow negative exponent", func() (any, error) { return c.pow(-1, 4) }, 0.25},
+		{"sqrt", func() (any, error) { return c.sqrt(json.Number("16")) }, 4.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, result, result)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := abs(math.MinInt); !errors.Is(err, errIntOverflow) {
+			t.Errorf("abs: expected overflow, got %v", err)
+		}
+		if _, err := c.pow(64, 2); !errors.Is(err, errIntOverflow) {
+			t.Errorf("pow: expected overflow, got %v", err)
+		}
+		if _, err := c.pow(0.5, -4); err == nil {
+			t.Errorf("pow: expected error for the root of a negative number")
+		}
+		if _, err := c.pow(400, 10.0); err == nil {
+			t.Errorf("pow: expected error for an infinite result")
+		}
+		if _, err := c.sqrt(-1); err == nil {
+			t.Errorf("sqrt: expected error for a negative number")
+		}
+	})
+}
+
+func TestSumAvg(t *testing.T) {
+	c := numberConverter{}
+	tests := []struct {
+		name     string
+		fn       func() (any, error)
+		expected any
+	}{
+		{"sum ints", func() (any, error) { return c.sum([]any{json.Number("1"), 2, int64(3)}) }, 6},
+		{"sum floats", func() (any, error) { return c.sum([]any{json.Number("1.5"), 2}) }, 3.5},
+		{"sum typed list", func() (any, error) { return c.sum([]int{1, 2, 3}) }, 6},
+		{"sum empty", func() (any, error) { return c.sum([]any{}) }, 0},
+		{"avg", func() (any, error) { return c.avg([]any{1, 2, json.Number("4.5")}) }, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, result, result)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := c.sum([]any{math.MaxInt, 1}); !errors.Is(err, errIntOverflow) {
+			t.Errorf("sum: expected overflow, got %v", err)
+		}
+		if _, err := c.sum([]any{1, "abc"}); err == nil {
+			t.Errorf("sum: expected error for a non-number")
+		}
+		if _, err := c.sum("abc"); err == nil {
+			t.Errorf("sum: expected error for a non-list")
+		}
+		if _, err := c.avg([]any{}); err == nil {
+			t.Errorf("avg: expected error for an empty list")
+		}
+	})
+}
+
+func TestMathTemplate(t *testing.T) {
+	data := map[string]any{"prices": []any{json.Number("3"), json.Number("1"), json.Number("2")}}
+
+	var out strings.Builder
+	template := `{{ add 1 2 3 }} {{ min .prices }} {{ max 4 9 }} {{ sum .prices }} {{ avg .prices }} {{ 150 | clamp 0 100 }} {{ 3 | pow 2 }}`
+	if err := executeTemplate(&out, template, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "6 1 9 6 2 100 9"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	if err := executeTemplate(&out, `{{ 10 | sub 3 | sub 2 }}`, nil, renderConfig{}); err != nil || out.String() != "5" {
+		t.Errorf("expected chained sub to give 5, got %q (%v)", out.String(), err)
+	}
+	if err := executeTemplate(&out, `{{ 10 | sub 3 2 }}`, nil, renderConfig{}); err == nil {
+		t.Errorf("expected error for sub with more than two numbers")
+	}
+
+	if err := executeTemplate(&out, `{{ 10 | div 0 }}`, nil, renderConfig{}); err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("expected division by zero error, got %v", err)
+	}
+}
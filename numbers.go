@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The data is decoded with json.Number and then converted to plain Go
+// numbers wherever that is exact: numbers a float64 holds, like 3, 0.1 or
+// 1.5, become float64 as with a plain JSON decoding, so the template
+// built-ins like printf, eq and gt and the truthiness of if work on them.
+// Integers above 2^53 become int64, and the numbers neither type holds,
+// integers beyond the int64 range and decimals with too many digits, stay
+// json.Number with their exact text. The helpers below convert these
+// exactly, and the decimal helpers compute with them without rounding.
+
+// decimalPattern matches the numbers the decimal helpers accept, the JSON
+// number syntax with an optional sign and fraction.
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// maxExactFloatInt is the largest integer from which on float64 can not
+// hold every integer.
+const maxExactFloatInt = 1 << 53
+
+// decodeJSON decodes a JSON value, with its numbers converted by
+// plainNumbers.
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return plainNumbers(v), nil
+}
+
+// plainNumbers converts the numbers of decoded data, in place, to float64
+// when a float64 holds them exactly, else to int64 when they are integers
+// in its range, else to json.Number. It converts the json.Number values of
+// the JSON decoder and the int and *big.Int values of gojq.
+func plainNumbers(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		return plainNumber(val)
+	case int:
+		return plainInt(int64(val))
+	case int64:
+		return plainInt(val)
+	case *big.Int:
+		if val.IsInt64() {
+			return plainInt(val.Int64())
+		}
+		return json.Number(val.String())
+	case map[string]any:
+		for k, item := range val {
+			val[k] = plainNumbers(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = plainNumbers(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func plainInt(n int64) any {
+	if n >= -maxExactFloatInt && n <= maxExactFloatInt {
+		return float64(n)
+	}
+	return n
+}
+
+// plainNumber converts a decoded number. Integers written without a
+// fraction or exponent that int64 does not hold stay json.Number even when a
+// float64 holds them, so an ID prints its digits whatever its magnitude.
+func plainNumber(n json.Number) any {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return plainInt(i)
+	}
+	if !strings.ContainsAny(string(n), ".eE") {
+		return n
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return n
+	}
+	exact, err := parseRat("number", string(n))
+	if err != nil {
+		return n
+	}
+	if !ratEqualsFloat(exact, f) {
+		return n
+	}
+	return f
+}
+
+// ratEqualsFloat reports whether a float holds a rational exactly, compared
+// with the shortest decimal form of the float like toRat does.
+func ratEqualsFloat(r *big.Rat, f float64) bool {
+	fr, err := toRat(f)
+	return err == nil && fr.Cmp(r) == 0
+}
+
+// toRat converts a number, or a string holding one, to an exact rational.
+// Floats are converted from their shortest decimal form, so 0.1 is 1/10 and
+// not the binary fraction closest to it.
+func toRat(v any) (*big.Rat, error) {
+	switch n := v.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), nil
+	case int64:
+		return new(big.Rat).SetInt64(n), nil
+	case float64:
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, errors.New("cannot convert an infinite or NaN float to a decimal")
+		}
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+		return r, nil
+	case *big.Int:
+		return new(big.Rat).SetInt(n), nil
+	case json.Number:
+		return parseRat("number", string(n))
+	case string:
+		return parseRat("string", strings.TrimSpace(n))
+	default:
+		return nil, fmt.Errorf("unsupported type for conversion to a decimal: %T", v)
+	}
+}
+
+// parseRat parses the text of a number or a string, named by kind in the
+// errors, which do not quote the text.
+func parseRat(kind, s string) (*big.Rat, error) {
+	if !decimalPattern.MatchString(s) {
+		return nil, fmt.Errorf("cannot convert %s to a decimal: invalid syntax", kind)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %s to a decimal: out of range", kind)
+	}
+	return r, nil
+}
+
+// decimalPlaces returns the number of digits after the decimal point of a
+// decimal.
+func decimalPlaces(r *big.Rat) int {
+	places := 0
+	ten := big.NewRat(10, 1)
+	for d := new(big.Rat).Set(r); !d.IsInt(); d.Mul(d, ten) {
+		places++
+	}
+	return places
+}
+
+// scaleOf returns the number of decimal places of a number: as written for
+// json.Number and strings, so 1.50 keeps its two places, and the places its
+// value needs for the other types.
+func scaleOf(v any, r *big.Rat) int {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = string(n)
+	case string:
+		s = strings.TrimSpace(n)
+	default:
+		return decimalPlaces(r)
+	}
+	mantissa, exp, _ := strings.Cut(strings.ToLower(s), "e")
+	places := 0
+	if _, frac, ok := strings.Cut(mantissa, "."); ok {
+		places = len(frac)
+	}
+	if exp != "" {
+		e, err := strconv.Atoi(exp)
+		if err != nil {
+			return decimalPlaces(r)
+		}
+		places -= e
+	}
+	return max(places, decimalPlaces(r))
+}
+
+func formatDecimal(r *big.Rat, places int) json.Number {
+	return json.Number(r.FloatString(places))
+}
+
+// compareNumbers compares two numbers exactly when one of them is a
+// json.Number or a big integer, which a float64 comparison could see equal.
+// It reports false when the values are not both numbers of this kind.
+func compareNumbers(a, b any) (int, bool) {
+	if !isExactNumber(a) && !isExactNumber(b) {
+		return 0, false
+	}
+	for _, v := range []any{a, b} {
+		switch v.(type) {
+		case int, int64, float64, json.Number, *big.Int:
+		default:
+			return 0, false
+		}
+	}
+	ar, aErr := toRat(a)
+	br, bErr := toRat(b)
+	if aErr != nil || bErr != nil {
+		return 0, false
+	}
+	return ar.Cmp(br), true
+}
+
+func isExactNumber(v any) bool {
+	switch v.(type) {
+	case json.Number, *big.Int:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberConverter converts the arguments of the conversion and math
+// helpers. By default it converts like toInt and toFloat, truncating
+// fractions; in strict mode, set with --strict-numbers, a conversion that
+// would lose a fraction or precision is an error.
+type numberConverter struct {
+	strict bool
+}
+
+func (c numberConverter) toInt64(v any) (int64, error) {
+	if !c.strict {
+		i, err := toInt(v)
+		return int64(i), err
+	}
+	r, err := toRat(v)
+	if err != nil {
+		return 0, err
+	}
+	if !r.IsInt() {
+		return 0, fmt.Errorf("cannot convert %T to int without losing the fraction (--strict-numbers)", v)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("cannot convert %T to int: out of range (--strict-numbers)", v)
+	}
+	return r.Num().Int64(), nil
+}
+
+func (c numberConverter) toInt(v any) (int, error) {
+	if !c.strict {
+		return toInt(v)
+	}
+	i, err := c.toInt64(v)
+	if err != nil {
+		return 0, err
+	}
+	if i < math.MinInt || i > math.MaxInt {
+		return 0, fmt.Errorf("cannot convert %T to int: out of range (--strict-numbers)", v)
+	}
+	return int(i), nil
+}
+
+func (c numberConverter) toFloat(v any) (float64, error) {
+	if !c.strict {
+		return toFloat(v)
+	}
+	if f, ok := v.(float64); ok {
+		return f, nil
+	}
+	r, err := toRat(v)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) {
+		return 0, fmt.Errorf("cannot convert %T to float: out of range (--strict-numbers)", v)
+	}
+	if back, _ := toRat(f); back.Cmp(r) != 0 {
+		return 0, fmt.Errorf("cannot convert %T to float without losing precision (--strict-numbers)", v)
+	}
+	return f, nil
+}
+
+func (c numberConverter) intPair(a, b any) (int, int, error) {
+	aInt, err := c.toInt(a)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot convert first argument to int: %w", err)
+	}
+	bInt, err := c.toInt(b)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot convert second argument to int: %w", err)
+	}
+	return aInt, bInt, nil
+}
+
+func (c numberConverter) floatPair(a, b any) (float64, float64, error) {
+	aFloat, err := c.toFloat(a)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot convert first argument to float: %w", err)
+	}
+	bFloat, err := c.toFloat(b)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot convert second argument to float: %w", err)
+	}
+	return aFloat, bFloat, nil
+}
+
+func toRatPair(name string, a, b any) (*big.Rat, *big.Rat, error) {
+	aRat, err := toRat(a)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	bRat, err := toRat(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return aRat, bRat, nil
+}
+
+// decimalAdd adds two decimals exactly, without the rounding of addf:
+// {{ decimalAdd "0.1" "0.2" }} is 0.3. The result has as many decimal
+// places as the more precise argument, so 1.50 plus 2 is 3.50.
+func decimalAdd(a, b any) (json.Number, error) {
+	aRat, bRat, err := toRatPair("decimalAdd", a, b)
+	if err != nil {
+		return "", err
+	}
+	places := max(scaleOf(a, aRat), scaleOf(b, bRat))
+	return formatDecimal(new(big.Rat).Add(aRat, bRat), places), nil
+}
+
+// decimalSub subtracts b from a exactly, called as (b, a) like sub, so
+// {{ .price | decimalSub .discount }} is the price minus the discount.
+func decimalSub(b, a any) (json.Number, error) {
+	aRat, bRat, err := toRatPair("decimalSub", a, b)
+	if err != nil {
+		return "", err
+	}
+	places := max(scaleOf(a, aRat), scaleOf(b, bRat))
+	return formatDecimal(new(big.Rat).Sub(aRat, bRat), places), nil
+}
+
+// decimalMul multiplies two decimals exactly.
+func decimalMul(a, b any) (json.Number, error) {
+	aRat, bRat, err := toRatPair("decimalMul", a, b)
+	if err != nil {
+		return "", err
+	}
+	places := scaleOf(a, aRat) + scaleOf(b, bRat)
+	return formatDecimal(new(big.Rat).Mul(aRat, bRat), places), nil
+}
+
+// roundNumber rounds a number to an integer, or to the number of decimal
+// places given first, with halves rounded away from zero: {{ .price | round
+// 2 }}. It works on the exact decimal value, so 2.675 rounds to 2.68.
+func roundNumber(args ...any) (json.Number, error) {
+	places := 0
+	switch len(args) {
+	case 1:
+	case 2:
+		var err error
+		if places, err = toInt(args[0]); err != nil {
+			return "", fmt.Errorf("round: invalid number of places: %w", err)
+		}
+		if places < 0 {
+			return "", fmt.Errorf("round: number of places must not be negative, got %d", places)
+		}
+		args = args[1:]
+	default:
+		return "", fmt.Errorf("round: expected 1 or 2 arguments, got %d", len(args))
+	}
+	r, err := toRat(args[0])
+	if err != nil {
+		return "", fmt.Errorf("round: %w", err)
+	}
+	return formatDecimal(r, places), nil
+}
+
+// floorNumber returns the greatest integer not greater than a number.
+func floorNumber(v any) (json.Number, error) {
+	r, err := toRat(v)
+	if err != nil {
+		return "", fmt.Errorf("floor: %w", err)
+	}
+	return json.Number(floorRat(r).String()), nil
+}
+
+// ceilNumber returns the least integer not less than a number.
+func ceilNumber(v any) (json.Number, error) {
+	r, err := toRat(v)
+	if err != nil {
+		return "", fmt.Errorf("ceil: %w", err)
+	}
+	n := floorRat(new(big.Rat).Neg(r))
+	return json.Number(n.Neg(n).String()), nil
+}
+
+// floorRat divides with Euclidean division, which rounds down as the
+// denominator of a big.Rat is always positive.
+func floorRat(r *big.Rat) *big.Int {
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
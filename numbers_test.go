@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	data, err := decodeJSON([]byte(`{"count": 0, "price": 0.10, "id": 9007199254740993, "big": 12345678901234567890, "huge": 100000000000000000000, "precise": 0.1000000000000000000001, "list": [3, 1e3]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"count":   0.0,
+		"price":   0.1,
+		"id":      int64(9007199254740993),
+		"big":     json.Number("12345678901234567890"),
+		"huge":    json.Number("100000000000000000000"),
+		"precise": json.Number("0.1000000000000000000001"),
+		"list":    []any{3.0, 1000.0},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %#v, got %#v", expected, data)
+	}
+
+	if _, err := decodeJSON([]byte(`{"a": 1} {"b": 2}`)); err == nil {
+		t.Errorf("expected error for data after the value")
+	}
+}
+
+func TestPlainNumbers(t *testing.T) {
+	big64, _ := new(big.Int).SetString("12345678901234567890", 10)
+	tests := []struct {
+		input    any
+		expected any
+	}{
+		{3, 3.0},
+		{int64(-9007199254740992), -9007199254740992.0},
+		{9007199254740993, int64(9007199254740993)},
+		{big.NewInt(42), 42.0},
+		{big64, json.Number("12345678901234567890")},
+		{json.Number("-0.5"), -0.5},
+		{json.Number("1e400"), json.Number("1e400")},
+		{json.Number("100000000000000000000"), json.Number("100000000000000000000")},
+		{json.Number("18446744073709551616"), json.Number("18446744073709551616")},
+		{json.Number("1e20"), 1e20},
+		{"3", "3"},
+	}
+	for _, tt := range tests {
+		if result := plainNumbers(tt.input); result != tt.expected {
+			t.Errorf("plainNumbers(%v): expected %#v, got %#v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestNumberConversions(t *testing.T) {
+	id := json.Number("9007199254740993")
+	if i, err := toInt(id); err != nil || i != 9007199254740993 {
+		t.Errorf("toInt: expected 9007199254740993, got %d (%v)", i, err)
+	}
+	if i, err := toInt(json.Number("3.9")); err != nil || i != 3 {
+		t.Errorf("toInt: expected 3, got %d (%v)", i, err)
+	}
+	if f, err := toFloat(json.Number("0.25")); err != nil || f != 0.25 {
+		t.Errorf("toFloat: expected 0.25, got %v (%v)", f, err)
+	}
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if _, err := toInt(n); err == nil {
+		t.Errorf("toInt: expected error for a big integer")
+	}
+	for _, v := range []any{1e20, -1e20, json.Number("100000000000000000000"), json.Number("1e20"), math.Inf(1), math.NaN(), float64(1 << 63)} {
+		if i, err := toInt(v); err == nil {
+			t.Errorf("toInt(%v): expected out of range error, got %d", v, i)
+		}
+	}
+	if i, err := toInt(float64(-1 << 63)); err != nil || i != math.MinInt {
+		t.Errorf("toInt: expected MinInt, got %d (%v)", i, err)
+	}
+	if f, err := toFloat(n); err != nil || f != 1.2345678901234568e29 {
+		t.Errorf("toFloat: expected 1.2345678901234568e29, got %v (%v)", f, err)
+	}
+}
+
+func TestNumberConverter(t *testing.T) {
+	lenient := numberConverter{}
+	strict := numberConverter{strict: true}
+
+	if i, err := lenient.toInt(3.9); err != nil || i != 3 {
+		t.Errorf("lenient toInt: expected 3, got %d (%v)", i, err)
+	}
+	if i, err := lenient.toInt64(json.Number("9007199254740993")); err != nil || i != 9007199254740993 {
+		t.Errorf("lenient toInt64: expected 9007199254740993, got %d (%v)", i, err)
+	}
+
+	tests := []struct {
+		name    string
+		fn      func(any) error
+		input   any
+		wantErr string
+	}{
+		{"toInt whole float", intErr(strict.toInt), 3.0, ""},
+		{"toInt fraction", intErr(strict.toInt), 3.9, "losing the fraction"},
+		{"toInt json.Number", intErr(strict.toInt), json.Number("42"), ""},
+		{"toInt json.Number exponent", intErr(strict.toInt), json.Number("1e3"), ""},
+		{"toInt json.Number fraction", intErr(strict.toInt), json.Number("2.5"), "losing the fraction"},
+		{"toInt string fraction", intErr(strict.toInt), "3.9", "losing the fraction"},
+		{"toInt string", intErr(strict.toInt), "abc", "cannot convert"},
+		{"toInt64 out of range", int64Err(strict.toInt64), json.Number("9223372036854775808"), "out of range"},
+		{"toFloat decimal", floatErr(strict.toFloat), json.Number("0.1"), ""},
+		{"toFloat big id", floatErr(strict.toFloat), json.Number("9007199254740993"), "losing precision"},
+		{"toFloat out of range", floatErr(strict.toFloat), "1e400", "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func intErr(fn func(any) (int, error)) func(any) error {
+	return func(v any) error {
+		_, err := fn(v)
+		return err
+	}
+}
+
+func int64Err(fn func(any) (int64, error)) func(any) error {
+	return func(v any) error {
+		_, err := fn(v)
+		return err
+	}
+}
+
+func floatErr(fn func(any) (float64, error)) func(any) error {
+	return func(v any) error {
+		_, err := fn(v)
+		return err
+	}
+}
+
+func TestDecimalHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (json.Number, error)
+		expected json.Number
+	}{
+		{"decimalAdd", func() (json.Number, error) { return decimalAdd("0.1", "0.2") }, "0.3"},
+		{"decimalAdd keeps places", func() (json.Number, error) { return decimalAdd(json.Number("1.50"), 2) }, "3.50"},
+		{"decimalAdd float", func() (json.Number, error) { return decimalAdd(0.1, 0.2) }, "0.3"},
+		{"decimalAdd big", func() (json.Number, error) { return decimalAdd(json.Number("9007199254740993"), 1) }, "9007199254740994"},
+		{"decimalSub", func() (json.Number, error) { return decimalSub("0.25", json.Number("10.00")) }, "9.75"},
+		{"decimalMul", func() (json.Number, error) { return decimalMul("19.99", 3) }, "59.97"},
+		{"round", func() (json.Number, error) { return roundNumber(2.5) }, "3"},
+		{"round negative", func() (json.Number, error) { return roundNumber("-2.5") }, "-3"},
+		{"round places", func() (json.Number, error) { return roundNumber(2, 2.675) }, "2.68"},
+		{"round pads", func() (json.Number, error) { return roundNumber(2, json.Number("1.5")) }, "1.50"},
+		{"floor", func() (json.Number, error) { return floorNumber(3.9) }, "3"},
+		{"floor negative", func() (json.Number, error) { return floorNumber("-3.1") }, "-4"},
+		{"ceil", func() (json.Number, error) { return ceilNumber(json.Number("3.1")) }, "4"},
+		{"ceil negative", func() (json.Number, error) { return ceilNumber(-3.9) }, "-3"},
+		{"ceil integer", func() (json.Number, error) { return ceilNumber(5) }, "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := decimalAdd("abc", 1); err == nil || !strings.HasPrefix(err.Error(), "decimalAdd:") {
+			t.Errorf("expected decimalAdd error, got %v", err)
+		}
+		if _, err := decimalAdd("0x10", 1); err == nil {
+			t.Errorf("expected error for a hexadecimal number")
+		}
+		if _, err := roundNumber(-1, 2.5); err == nil {
+			t.Errorf("expected error for negative places")
+		}
+		if _, err := floorNumber(nil); err == nil {
+			t.Errorf("expected error for nil")
+		}
+	})
+}
+
+func TestCompareNumbers(t *testing.T) {
+	if cmp := compareValues(json.Number("9007199254740993"), json.Number("9007199254740992"), "auto"); cmp != 1 {
+		t.Errorf("expected 1 for large integers, got %d", cmp)
+	}
+	if cmp := compareValues(json.Number("10"), 9.5, "auto"); cmp != 1 {
+		t.Errorf("expected 1, got %d", cmp)
+	}
+	if !valuesEqual(json.Number("2.50"), 2.5) {
+		t.Errorf("expected 2.50 to equal 2.5")
+	}
+	if _, ok := compareNumbers(json.Number("1"), "1"); ok {
+		t.Errorf("expected strings not to be compared as exact numbers")
+	}
+}
+
+func TestOutOfRangeIntTemplate(t *testing.T) {
+	data, err := decodeJSON([]byte(`{"big": 100000000000000000000, "float": 1e20}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, config := range []renderConfig{{}, {strictNumbers: true}} {
+		for _, tpl := range []string{`{{ toInt .big }}`, `{{ int64 .big }}`, `{{ toInt .float }}`, `{{ int64 .float }}`} {
+			var out strings.Builder
+			err := executeTemplate(&out, tpl, data, config)
+			if err == nil || !strings.Contains(err.Error(), "out of range") {
+				t.Errorf("%s (strict %v): expected out of range error, got %v (%q)", tpl, config.strictNumbers, err, out.String())
+			}
+		}
+	}
+}
+
+func TestStrictNumbersTemplate(t *testing.T) {
+	data := map[string]any{"price": json.Number("3.9"), "id": json.Number("9007199254740993")}
+
+	var out strings.Builder
+	if err := executeTemplate(&out, `{{ toInt .price }} {{ add .id 1 }}`, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "3 9007199254740994" {
+		t.Errorf("expected '3 9007199254740994', got %q", out.String())
+	}
+
+	err := executeTemplate(&out, `{{ toInt .price }}`, data, renderConfig{strictNumbers: true})
+	if err == nil || !strings.Contains(err.Error(), "losing the fraction") {
+		t.Errorf("expected strict error, got %v", err)
+	}
+}
+
+func TestConversionErrorsOmitValues(t *testing.T) {
+	strict := numberConverter{strict: true}
+	tests := []struct {
+		value string
+		fn    func(string) error
+	}{
+		{"99999999999999999999999", func(v string) error { _, err := toInt(v); return err }},
+		{"1e999", func(v string) error { _, err := toFloat(v); return err }},
+		{"1e999", func(v string) error { _, err := toFloat(json.Number(v)); return err }},
+		{"1e999", func(v string) error { _, err := toInt(json.Number(v)); return err }},
+		{"12.5", func(v string) error { _, err := strict.toInt(json.Number(v)); return err }},
+		{"abc", func(v string) error { _, err := decimalAdd(v, 1); return err }},
+	}
+	for _, tt := range tests {
+		err := tt.fn(tt.value)
+		if err == nil {
+			t.Fatalf("%s: expected error", tt.value)
+		}
+		if strings.Contains(err.Error(), tt.value) {
+			t.Errorf("expected the error not to quote %s, got %v", tt.value, err)
+		}
+	}
+}
+
+func TestNumbersInTemplates(t *testing.T) {
+	input := `{"count": 0, "n": 3, "p": 1.5, "price": 0.10, "id": 9007199254740993, "big": 12345678901234567890}`
+	template := `{{ if .count }}some{{ else }}none{{ end }} {{ printf "%.2f" .price }} {{ gt .p 1.0 }} {{ eq .n 3.0 }} {{ .price }} {{ .id }} {{ .big }} {{ add .id 1 }}`
+	expected := "none 0.10 true true 0.1 9007199254740993 12345678901234567890 9007199254740994"
+
+	data, err := decodeJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out strings.Builder
+	if err := executeTemplate(&out, template, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	data, _ = decodeJSON([]byte(input))
+	queried, err := runJQ(".", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out.Reset()
+	if err := executeTemplate(&out, template, queried, renderConfig{}); err != nil {
+		t.Fatalf("query: unexpected error: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("query: expected %q, got %q", expected, out.String())
+	}
+}
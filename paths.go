@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses paths like "a.b[0].c-d" or `a["key.with.dots"]`. Keys are
+// separated by dots and may contain any other character, list indexes and
+// quoted keys are written in brackets. Negative indexes count from the end.
+func parsePath(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	path = strings.TrimPrefix(path, "$")
+
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			segment, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path '%s': %w", path, err)
+			}
+			segments = append(segments, segment)
+			i += n
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, pathSegment{key: path[i : i+end]})
+			i += end
+		}
+	}
+
+	return segments, nil
+}
+
+// parseBracket parses a [n], ["key"] or ['key'] segment at the start of s and
+// returns the number of bytes consumed.
+func parseBracket(s string) (pathSegment, int, error) {
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		quote := s[1]
+		var b strings.Builder
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\\' && i+1 < len(s):
+				i++
+				b.WriteByte(s[i])
+			case s[i] == quote:
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return pathSegment{}, 0, fmt.Errorf("expected ']' after quoted key")
+				}
+				return pathSegment{key: b.String()}, i + 2, nil
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return pathSegment{}, 0, fmt.Errorf("unterminated quoted key")
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return pathSegment{}, 0, fmt.Errorf("missing ']'")
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(s[1:end]))
+	if err != nil {
+		return pathSegment{}, 0, fmt.Errorf("invalid index '%s'", s[1:end])
+	}
+	return pathSegment{index: index, isIndex: true}, end + 1, nil
+}
+
+func listIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+func getSegment(v any, segment pathSegment) (any, bool) {
+	if segment.isIndex {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, false
+		}
+		i, ok := listIndex(segment.index, len(list))
+		if !ok {
+			return nil, false
+		}
+		return list[i], true
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	value, ok := m[segment.key]
+	return value, ok
+}
+
+func getPath(v any, segments []pathSegment) (any, bool) {
+	for _, segment := range segments {
+		var ok bool
+		if v, ok = getSegment(v, segment); !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// lookupPath returns the value at the path in nested maps and lists.
+func lookupPath(v any, path string) (any, bool) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	return getPath(v, segments)
+}
+
+// get returns the value at the path. It is called either with the data only
+// (get "a.b" .) or with a default value returned when the path does not exist
+// or holds null (get "a.b" "default" .).
+func get(path string, args ...any) (any, error) {
+	var defaultVal, data any
+	switch len(args) {
+	case 1:
+		data = args[0]
+	case 2:
+		defaultVal, data = args[0], args[1]
+	default:
+		return nil, fmt.Errorf("get: expected 2 or 3 arguments, got %d", len(args)+1)
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	value, ok := getPath(data, segments)
+	if !ok || value == nil {
+		return defaultVal, nil
+	}
+	return value, nil
+}
+
+// hasKey reports whether the path exists in the data.
+func hasKey(path string, data any) (bool, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return false, fmt.Errorf("hasKey: %w", err)
+	}
+	_, ok := getPath(data, segments)
+	return ok, nil
+}
+
+// dig returns the value found by following the keys (strings for maps,
+// integers for lists) given before the data, or nil if any of them is
+// missing.
+func dig(args ...any) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("dig: expected at least 2 arguments, got %d", len(args))
+	}
+
+	segments := make([]pathSegment, len(args)-1)
+	for i, key := range args[:len(args)-1] {
+		switch k := key.(type) {
+		case string:
+			segments[i] = pathSegment{key: k}
+		case int, int64, float64, json.Number:
+			index, err := toInt(k)
+			if err != nil {
+				return nil, fmt.Errorf("dig: %w", err)
+			}
+			segments[i] = pathSegment{index: index, isIndex: true}
+		default:
+			return nil, fmt.Errorf("dig: unsupported key type %T", key)
+		}
+	}
+
+	value, _ := getPath(args[len(args)-1], segments)
+	return value, nil
+}
+
+// setPath returns a copy of v with the value stored at the path. Maps along
+// the path are copied, missing maps are created.
+func setPath(v any, segments []pathSegment, value any) (any, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	segment := segments[0]
+	if segment.isIndex {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot index %T", v)
+		}
+		i, ok := listIndex(segment.index, len(list))
+		if !ok {
+			return nil, fmt.Errorf("index %d out of range", segment.index)
+		}
+		child, err := setPath(list[i], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		result := append([]any{}, list...)
+		result[i] = child
+		return result, nil
+	}
+
+	var m map[string]any
+	switch val := v.(type) {
+	case map[string]any:
+		m = val
+	case nil:
+		m = map[string]any{}
+	default:
+		return nil, fmt.Errorf("cannot set key '%s' on %T", segment.key, v)
+	}
+
+	child, err := setPath(m[segment.key], segments[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]any, len(m)+1)
+	for k, item := range m {
+		result[k] = item
+	}
+	result[segment.key] = child
+	return result, nil
+}
+
+// unsetPath returns a copy of v without the value at the path.
+func unsetPath(v any, segments []pathSegment) any {
+	if len(segments) == 0 {
+		return v
+	}
+
+	segment := segments[0]
+	last := len(segments) == 1
+
+	if segment.isIndex {
+		list, ok := v.([]any)
+		if !ok {
+			return v
+		}
+		i, ok := listIndex(segment.index, len(list))
+		if !ok {
+			return v
+		}
+		if last {
+			return append(append([]any{}, list[:i]...), list[i+1:]...)
+		}
+		result := append([]any{}, list...)
+		result[i] = unsetPath(list[i], segments[1:])
+		return result
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if _, ok := m[segment.key]; !ok {
+		return v
+	}
+	result := make(map[string]any, len(m))
+	for k, item := range m {
+		result[k] = item
+	}
+	if last {
+		delete(result, segment.key)
+	} else {
+		result[segment.key] = unsetPath(m[segment.key], segments[1:])
+	}
+	return result
+}
+
+// set returns a copy of the data with the value stored at the path.
+func set(path string, value any, data any) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("set: %w", err)
+	}
+	result, err := setPath(data, segments, value)
+	if err != nil {
+		return nil, fmt.Errorf("set: %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// unset returns a copy of the data without the value at the path.
+func unset(path string, data any) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("unset: %w", err)
+	}
+	return unsetPath(data, segments), nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPathData() map[string]any {
+	return map[string]any{
+		"a": map[string]any{
+			"b": []any{
+				map[string]any{"c-d": "value", "empty": nil},
+			},
+		},
+		"dotted.key": 1.0,
+		"list":       []any{"x", "y", "z"},
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []pathSegment
+		hasError bool
+	}{
+		{"a.b", []pathSegment{{key: "a"}, {key: "b"}}, false},
+		{".a[0].c-d", []pathSegment{{key: "a"}, {index: 0, isIndex: true}, {key: "c-d"}}, false},
+		{`["dotted.key"]`, []pathSegment{{key: "dotted.key"}}, false},
+		{`a['it\'s']`, []pathSegment{{key: "a"}, {key: "it's"}}, false},
+		{"list[-1]", []pathSegment{{key: "list"}, {index: -1, isIndex: true}}, false},
+		{".", []pathSegment{}, false},
+		{"a[x]", nil, true},
+		{"a[0", nil, true},
+		{`a["b`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := parsePath(tt.path)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	data := testPathData()
+
+	tests := []struct {
+		name     string
+		path     string
+		args     []any
+		expected any
+		hasError bool
+	}{
+		{"nested with dash", "a.b[0].c-d", []any{data}, "value", false},
+		{"quoted key", `["dotted.key"]`, []any{data}, 1.0, false},
+		{"negative index", "list[-1]", []any{data}, "z", false},
+		{"missing without default", "a.x.y", []any{data}, nil, false},
+		{"missing with default", "a.x.y", []any{"default", data}, "default", false},
+		{"null with default", "a.b[0].empty", []any{"default", data}, "default", false},
+		{"index out of range", "list[5]", []any{"default", data}, "default", false},
+		{"index on map", "a[0]", []any{"default", data}, "default", false},
+		{"invalid path", "a[", []any{data}, nil, true},
+		{"missing data", "a", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := get(tt.path, tt.args...)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHasKeyAndDig(t *testing.T) {
+	data := testPathData()
+
+	for path, expected := range map[string]bool{
+		"a.b[0].c-d":   true,
+		"a.b[0].empty": true,
+		"a.b[1]":       false,
+		"a.c":          false,
+	} {
+		result, err := hasKey(path, data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != expected {
+			t.Errorf("hasKey(%s) expected %v, got %v", path, expected, result)
+		}
+	}
+
+	result, err := dig("a", "b", 0, "c-d", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "value" {
+		t.Errorf("expected value, got %v", result)
+	}
+
+	result, err = dig("a", "missing", "c", data)
+	if err != nil || result != nil {
+		t.Errorf("expected nil without error, got %v, %v", result, err)
+	}
+
+	if _, err := dig(true, data); err == nil {
+		t.Errorf("expected error for unsupported key type")
+	}
+}
+
+func TestSetAndUnset(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		data := testPathData()
+		result, err := set("a.b[0].c-d", "new", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v, _ := lookupPath(result, "a.b[0].c-d"); v != "new" {
+			t.Errorf("expected new, got %v", v)
+		}
+		if v, _ := lookupPath(data, "a.b[0].c-d"); v != "value" {
+			t.Errorf("original data was modified: %v", v)
+		}
+	})
+
+	t.Run("set creates maps", func(t *testing.T) {
+		result, err := set("x.y.z", 1, map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v, _ := lookupPath(result, "x.y.z"); v != 1 {
+			t.Errorf("expected 1, got %v", v)
+		}
+	})
+
+	t.Run("set errors", func(t *testing.T) {
+		if _, err := set("list[10]", 1, testPathData()); err == nil {
+			t.Errorf("expected error for index out of range")
+		}
+		if _, err := set("list.a", 1, testPathData()); err == nil {
+			t.Errorf("expected error for key on a list")
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		data := testPathData()
+		result, err := unset("list[1]", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v, _ := lookupPath(result, "list"); !equalValues(v, []any{"x", "z"}) {
+			t.Errorf("expected [x z], got %v", v)
+		}
+
+		result, err = unset("a.b[0].c-d", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok, _ := hasKey("a.b[0].c-d", result); ok {
+			t.Errorf("key was not removed")
+		}
+		if ok, _ := hasKey("a.b[0].c-d", data); !ok {
+			t.Errorf("original data was modified")
+		}
+		if len(data["list"].([]any)) != 3 {
+			t.Errorf("original list was modified")
+		}
+	})
+}
+
+func TestPathTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"get", `{{ get "a.b[0].c-d" . }}`, "value"},
+		{"get default", `{{ get "a.nope" "N/A" . }}`, "N/A"},
+		{"hasKey", `{{ if hasKey "a.b" . }}yes{{ end }}`, "yes"},
+		{"set", `{{ $d := set "a.new" 1 . }}{{ $d.a.new }} {{ hasKey "a.new" . }}`, "1 false"},
+		{"dig", `{{ dig "list" 0 . }}`, "x"},
+		{"sortBy with index path", `{{ range sortBy "[0]" .pairs }}{{ index . 1 }}{{ end }}`, "ab"},
+	}
+
+	data := testPathData()
+	data["pairs"] = []any{[]any{2.0, "b"}, []any{1.0, "a"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := executeTemplate(&buf, tt.template, data, renderConfig{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
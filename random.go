@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// randomSource generates the random values of a render. It reads
+// crypto/rand, or a deterministic stream when a seed is set, and takes the
+// time of time-ordered identifiers from the render's clock, so with --seed
+// and --now every render generates the same values.
+type randomSource struct {
+	r   io.Reader
+	now func() time.Time
+}
+
+func newRandomSource(config renderConfig) *randomSource {
+	return &randomSource{r: config.random(), now: config.currentTime}
+}
+
+func (s *randomSource) read(name string, b []byte) error {
+	if _, err := io.ReadFull(s.r, b); err != nil {
+		return fmt.Errorf("%s: failed to read random bytes: %w", name, err)
+	}
+	return nil
+}
+
+// uintn returns a uniform random number in [0, n), rejecting the values
+// which would make the smaller results more likely.
+func (s *randomSource) uintn(name string, n uint64) (uint64, error) {
+	limit := math.MaxUint64 - (math.MaxUint64%n+1)%n
+	var b [8]byte
+	for {
+		if err := s.read(name, b[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint64(b[:]); v <= limit {
+			return v % n, nil
+		}
+	}
+}
+
+// unixMillis returns the current time of the render as the 48-bit
+// millisecond timestamp of UUIDv7 and ULID.
+func (s *randomSource) unixMillis(name string) ([6]byte, error) {
+	var b [6]byte
+	ms := s.now().UnixMilli()
+	if ms < 0 || ms >= 1<<48 {
+		return b, fmt.Errorf("%s: time %s can't be written in 48 bits", name, s.now().Format(time.RFC3339))
+	}
+	var full [8]byte
+	binary.BigEndian.PutUint64(full[:], uint64(ms))
+	copy(b[:], full[2:])
+	return b, nil
+}
+
+func formatUUID(b [16]byte) string {
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// uuidv4 returns a random UUID (RFC 9562 version 4).
+func (s *randomSource) uuidv4() (string, error) {
+	var b [16]byte
+	if err := s.read("uuidv4", b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+// uuidv7 returns a time-ordered UUID (RFC 9562 version 7): a millisecond
+// Unix timestamp followed by random bits.
+func (s *randomSource) uuidv7() (string, error) {
+	var b [16]byte
+	ms, err := s.unixMillis("uuidv7")
+	if err != nil {
+		return "", err
+	}
+	copy(b[:6], ms[:])
+	if err := s.read("uuidv7", b[6:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulid returns a ULID: a millisecond Unix timestamp and 80 random bits,
+// written as 26 characters of Crockford's base32, which sort in time order.
+func (s *randomSource) ulid() (string, error) {
+	var b [16]byte
+	ms, err := s.unixMillis("ulid")
+	if err != nil {
+		return "", err
+	}
+	copy(b[:6], ms[:])
+	if err := s.read("ulid", b[6:]); err != nil {
+		return "", err
+	}
+
+	// 26 characters of 5 bits are 130 bits, the first character has the
+	// two missing high bits as zeros.
+	var out [26]byte
+	for i := range out {
+		var v byte
+		for bit := 5*i - 2; bit < 5*i+3; bit++ {
+			v <<= 1
+			if bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockfordAlphabet[v]
+	}
+	return string(out[:]), nil
+}
+
+// randInt returns a random integer between min and max, including min but
+// not max.
+func (s *randomSource) randInt(min, max any) (int, error) {
+	lo, hi, err := toIntPair(min, max)
+	if err != nil {
+		return 0, fmt.Errorf("randInt: %w", err)
+	}
+	if hi <= lo {
+		return 0, fmt.Errorf("randInt: max (%d) must be greater than min (%d)", hi, lo)
+	}
+	n, err := s.uintn("randInt", uint64(hi)-uint64(lo))
+	if err != nil {
+		return 0, err
+	}
+	return lo + int(n), nil
+}
+
+// randChoice returns a random element of a list.
+func (s *randomSource) randChoice(v any) (any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("randChoice: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("randChoice: the list is empty")
+	}
+	i, err := s.uintn("randChoice", uint64(len(list)))
+	if err != nil {
+		return nil, err
+	}
+	return list[i], nil
+}
+
+// shuffle returns a copy of a list in random order.
+func (s *randomSource) shuffle(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("shuffle: %w", err)
+	}
+	result := append([]any{}, list...)
+	for i := len(result) - 1; i > 0; i-- {
+		j, err := s.uintn("shuffle", uint64(i+1))
+		if err != nil {
+			return nil, err
+		}
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+func randomLength(name string, n any) (int, error) {
+	length, err := toInt(n)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid length: %w", name, err)
+	}
+	if length < 0 {
+		return 0, fmt.Errorf("%s: length must not be negative, got %d", name, length)
+	}
+	return length, nil
+}
+
+const alphaNum = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// randAlphaNum returns n random letters and digits, for passwords and
+// tokens.
+func (s *randomSource) randAlphaNum(n any) (string, error) {
+	length, err := randomLength("randAlphaNum", n)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, length)
+	for i := range b {
+		j, err := s.uintn("randAlphaNum", uint64(len(alphaNum)))
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphaNum[j]
+	}
+	return string(b), nil
+}
+
+// randBytes returns n random bytes as a string, to be encoded:
+// {{ randBytes 32 | base64Encode }}.
+func (s *randomSource) randBytes(n any) (string, error) {
+	length, err := randomLength("randBytes", n)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, length)
+	if err := s.read("randBytes", b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
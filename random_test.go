@@ -0,0 +1,190 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidPattern = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func seededSource(seed int64) *randomSource {
+	return newRandomSource(renderConfig{
+		now:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		seed:   seed,
+		seeded: true,
+	})
+}
+
+func TestUUIDs(t *testing.T) {
+	for _, s := range []*randomSource{newRandomSource(renderConfig{}), seededSource(1)} {
+		v4, err := s.uuidv4()
+		if err != nil {
+			t.Fatalf("uuidv4: unexpected error: %v", err)
+		}
+		if !uuidPattern.MatchString(v4) || v4[14] != '4' {
+			t.Errorf("uuidv4: invalid UUID %s", v4)
+		}
+
+		v7, err := s.uuidv7()
+		if err != nil {
+			t.Fatalf("uuidv7: unexpected error: %v", err)
+		}
+		if !uuidPattern.MatchString(v7) || v7[14] != '7' {
+			t.Errorf("uuidv7: invalid UUID %s", v7)
+		}
+
+		id, err := s.ulid()
+		if err != nil {
+			t.Fatalf("ulid: unexpected error: %v", err)
+		}
+		if !ulidPattern.MatchString(id) {
+			t.Errorf("ulid: invalid ULID %s", id)
+		}
+	}
+
+	s := seededSource(1)
+	first, _ := s.uuidv4()
+	second, _ := s.uuidv4()
+	if first == second {
+		t.Errorf("expected different UUIDs, got %s twice", first)
+	}
+
+	// The timestamp of 2025-01-01T00:00:00Z is 1735689600000 ms.
+	if v7, _ := s.uuidv7(); !strings.HasPrefix(v7, "01941f29-7c00-7") {
+		t.Errorf("uuidv7: expected the timestamp of now, got %s", v7)
+	}
+	if id, _ := s.ulid(); !strings.HasPrefix(id, "01JGFJJZ00") {
+		t.Errorf("ulid: expected the timestamp of now, got %s", id)
+	}
+
+	early := newRandomSource(renderConfig{now: time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)})
+	if _, err := early.uuidv7(); err == nil {
+		t.Errorf("uuidv7: expected error for a time before the epoch")
+	}
+	if _, err := early.ulid(); err == nil {
+		t.Errorf("ulid: expected error for a time before the epoch")
+	}
+}
+
+func TestRandInt(t *testing.T) {
+	s := seededSource(1)
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		n, err := s.randInt(-2, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n < -2 || n >= 3 {
+			t.Fatalf("expected a number in [-2, 3), got %d", n)
+		}
+		seen[n] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("expected every number in [-2, 3), got %v", seen)
+	}
+
+	if n, err := s.randInt(5.0, "6"); err != nil || n != 5 {
+		t.Errorf("expected 5, got %d (%v)", n, err)
+	}
+	if _, err := s.randInt(3, 3); err == nil {
+		t.Errorf("expected error for an empty range")
+	}
+	if _, err := s.randInt("a", 3); err == nil {
+		t.Errorf("expected error for an invalid min")
+	}
+}
+
+func TestRandChoiceAndShuffle(t *testing.T) {
+	s := seededSource(1)
+	list := []any{"a", "b", "c", "d", "e"}
+
+	choice, err := s.randChoice(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if indexOf(choice, list) < 0 {
+		t.Errorf("expected an element of the list, got %v", choice)
+	}
+	if _, err := s.randChoice([]any{}); err == nil {
+		t.Errorf("expected error for an empty list")
+	}
+	if _, err := s.randChoice("abc"); err == nil {
+		t.Errorf("expected error for a string")
+	}
+
+	shuffled, err := s.shuffle(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sorted := make([]string, len(shuffled))
+	for i, v := range shuffled {
+		sorted[i] = v.(string)
+	}
+	sort.Strings(sorted)
+	if strings.Join(sorted, "") != "abcde" {
+		t.Errorf("expected a permutation of the list, got %v", shuffled)
+	}
+	if list[0] != "a" || list[4] != "e" {
+		t.Errorf("expected the list to be unchanged, got %v", list)
+	}
+	if result, err := s.shuffle([]any{}); err != nil || len(result) != 0 {
+		t.Errorf("expected an empty list, got %v (%v)", result, err)
+	}
+}
+
+func TestRandStrings(t *testing.T) {
+	s := seededSource(1)
+
+	token, err := s.randAlphaNum(24)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !regexp.MustCompile(`^[A-Za-z0-9]{24}$`).MatchString(token) {
+		t.Errorf("expected 24 letters and digits, got %q", token)
+	}
+
+	b, err := s.randBytes(16.0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b) != 16 {
+		t.Errorf("expected 16 bytes, got %d", len(b))
+	}
+	if empty, _ := s.randBytes(0); empty != "" {
+		t.Errorf("expected no bytes, got %q", empty)
+	}
+
+	if _, err := s.randAlphaNum(-1); err == nil {
+		t.Errorf("expected error for a negative length")
+	}
+	if _, err := s.randBytes("many"); err == nil {
+		t.Errorf("expected error for an invalid length")
+	}
+}
+
+func TestRandomTemplateReproducible(t *testing.T) {
+	tpl := `{{ uuidv4 }} {{ uuidv7 }} {{ ulid }} {{ randInt 1 100 }} {{ randChoice .items }} {{ shuffle .items }} {{ randAlphaNum 8 }} {{ randBytes 8 | base64Encode }}`
+	data := map[string]any{"items": []any{"a", "b", "c"}}
+
+	render := func(config renderConfig) string {
+		var buf strings.Builder
+		if err := executeTemplate(&buf, tpl, data, config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	config := renderConfig{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), seed: 42, seeded: true}
+	if first, second := render(config), render(config); first != second {
+		t.Errorf("expected the same output with a seed, got\n%s\n%s", first, second)
+	}
+	if first, second := render(renderConfig{}), render(renderConfig{}); first == second {
+		t.Errorf("expected different output without a seed, got %s twice", first)
+	}
+}
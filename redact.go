@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultSensitiveKeys matches the names of the data keys whose values are
+// sensitive.
+var defaultSensitiveKeys = regexp.MustCompile(`(?i)passw(or)?d|secret|token|api[_-]?key|private[_-]?key|credential`)
+
+const (
+	redactedValue = "[REDACTED]"
+
+	// minSubstringLength is the length of the shortest value masked inside
+	// words. Shorter values, like "1" or "on", are masked only as whole
+	// words, so they do not mask unrelated parts of the messages.
+	minSubstringLength = 4
+)
+
+// redactor masks sensitive values in error messages. The values are the
+// ones under sensitive keys of the data, and the decrypted values, in the
+// forms of sensitiveForms. The rendered output is not affected. The helpers
+// avoid quoting values in their errors; the redactor catches the ones that
+// still do.
+type redactor struct {
+	values   map[string]bool
+	replacer *strings.Replacer
+	words    []string
+}
+
+func newRedactor() *redactor {
+	return &redactor{values: map[string]bool{}}
+}
+
+// add marks a value, or every value in a map or a list, as sensitive. It
+// can be called on a nil redactor, which does nothing.
+func (r *redactor) add(v any) {
+	if r == nil {
+		return
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for _, item := range val {
+			r.add(item)
+		}
+	case []any:
+		for _, item := range val {
+			r.add(item)
+		}
+	case float64:
+		// Large numbers are written in exponent form by %v, and in full by
+		// the helpers converting them.
+		r.addString(strconv.FormatFloat(val, 'f', -1, 64))
+		r.addString(fmt.Sprint(val))
+	default:
+		s, _ := toString(val)
+		r.addString(s)
+	}
+}
+
+func (r *redactor) addString(s string) {
+	if s == "" {
+		return
+	}
+	for _, form := range sensitiveForms(s) {
+		if !r.values[form] {
+			r.values[form] = true
+			r.replacer = nil
+		}
+	}
+}
+
+// sensitiveForms returns a value with the transformations of the string and
+// encoding helpers that keep it readable: upper, lower and title case,
+// base64 in its four variants and hex.
+func sensitiveForms(s string) []string {
+	b := []byte(s)
+	return []string{
+		s,
+		strings.ToUpper(s),
+		strings.ToLower(s),
+		title(s),
+		base64.StdEncoding.EncodeToString(b),
+		base64.URLEncoding.EncodeToString(b),
+		base64.RawStdEncoding.EncodeToString(b),
+		base64.RawURLEncoding.EncodeToString(b),
+		hex.EncodeToString(b),
+		strings.ToUpper(hex.EncodeToString(b)),
+	}
+}
+
+// addSensitiveKeys marks the values under keys matching one of the patterns
+// as sensitive, at any depth of the data.
+func (r *redactor) addSensitiveKeys(data any, patterns ...*regexp.Regexp) {
+	switch val := data.(type) {
+	case map[string]any:
+		for key, item := range val {
+			if matchesAny(key, patterns) {
+				r.add(item)
+			} else {
+				r.addSensitiveKeys(item, patterns...)
+			}
+		}
+	case []any:
+		for _, item := range val {
+			r.addSensitiveKeys(item, patterns...)
+		}
+	}
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces the sensitive values in a message. Longer values are
+// replaced first, so a value containing another is masked entirely, and
+// values shorter than minSubstringLength only where they are whole words.
+func (r *redactor) redact(s string) string {
+	if r == nil || len(r.values) == 0 {
+		return s
+	}
+	if r.replacer == nil {
+		values := make([]string, 0, len(r.values))
+		for value := range r.values {
+			values = append(values, value)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if len(values[i]) != len(values[j]) {
+				return len(values[i]) > len(values[j])
+			}
+			return values[i] < values[j]
+		})
+
+		oldnew := make([]string, 0, 2*len(values))
+		r.words = r.words[:0]
+		for _, value := range values {
+			if len(value) < minSubstringLength {
+				r.words = append(r.words, value)
+			} else {
+				oldnew = append(oldnew, value, redactedValue)
+			}
+		}
+		r.replacer = strings.NewReplacer(oldnew...)
+	}
+	s = r.replacer.Replace(s)
+	for _, word := range r.words {
+		s = replaceWord(s, word, redactedValue)
+	}
+	return s
+}
+
+// replaceWord replaces the occurrences of word in s that are not preceded
+// or followed by a letter or a digit.
+func replaceWord(s, word, replacement string) string {
+	var b strings.Builder
+	start := 0
+	for from := 0; ; {
+		i := strings.Index(s[from:], word)
+		if i < 0 {
+			break
+		}
+		i += from
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (i > 0 && isWordRune(before)) || (end < len(s) && isWordRune(after)) {
+			from = i + 1
+			continue
+		}
+		b.WriteString(s[start:i])
+		b.WriteString(replacement)
+		start, from = end, end
+	}
+	b.WriteString(s[start:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
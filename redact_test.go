@@ -0,0 +1,127 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactorSensitiveKeys(t *testing.T) {
+	data := map[string]any{
+		"name": "app",
+		"db": map[string]any{
+			"user":     "admin",
+			"password": "s3cretpw",
+			"port":     5432.0,
+		},
+		"services": []any{
+			map[string]any{"name": "api", "apiKey": "key-123456"},
+			map[string]any{"name": "web", "API_TOKEN": 987654321.0},
+		},
+		"secrets": map[string]any{"list": []any{"first-secret", "second-secret"}},
+		"pin":     "4711",
+	}
+
+	r := newRedactor()
+	r.addSensitiveKeys(data, defaultSensitiveKeys)
+
+	message := "admin s3cretpw key-123456 9.87654321e+08 first-secret second-secret 4711 app api 5432"
+	expected := "admin [REDACTED] [REDACTED] [REDACTED] [REDACTED] [REDACTED] 4711 app api 5432"
+	if result := r.redact(message); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	r.addSensitiveKeys(data, regexp.MustCompile(`^pin$`))
+	if result := r.redact("987654321"); result != redactedValue {
+		t.Errorf("expected numbers to be masked in both forms, got %q", result)
+	}
+	if result := r.redact("pin 4711"); result != "pin [REDACTED]" {
+		t.Errorf("expected the custom pattern to be masked, got %q", result)
+	}
+}
+
+func TestRedactorValues(t *testing.T) {
+	r := newRedactor()
+	if result := r.redact("nothing to mask"); result != "nothing to mask" {
+		t.Errorf("expected the message unchanged, got %q", result)
+	}
+
+	r.add("abc")
+	r.add("")
+	r.add(nil)
+	r.add("pass")
+	r.add("password123")
+	if result := r.redact("abc: password123 pass abcdef"); result != "[REDACTED]: [REDACTED] [REDACTED] abcdef" {
+		t.Errorf("expected the longer value masked entirely and short values as words, got %q", result)
+	}
+
+	r.add(7.0)
+	r.add("on")
+	for message, expected := range map[string]string{
+		"7":            "[REDACTED]",
+		"pin 7, 17 77": "pin [REDACTED], 17 77",
+		"on online":    "[REDACTED] online",
+		"ON/on":        "[REDACTED]/[REDACTED]",
+	} {
+		if result := r.redact(message); result != expected {
+			t.Errorf("redact(%q): expected %q, got %q", message, expected, result)
+		}
+	}
+
+	r.add("s3cretpw")
+	for _, message := range []string{"S3CRETPW", "czNjcmV0cHc="} {
+		if result := r.redact("value " + message); result != "value [REDACTED]" {
+			t.Errorf("expected the transformed value %q masked, got %q", message, result)
+		}
+	}
+
+	var none *redactor
+	none.add("s3cretpw")
+	if result := none.redact("s3cretpw"); result != "s3cretpw" {
+		t.Errorf("expected a nil redactor to do nothing, got %q", result)
+	}
+}
+
+func TestRedactTemplateError(t *testing.T) {
+	data := map[string]any{"db": map[string]any{"password": "s3cretpw", "token": "pw>>>?x", "pin": "x1"}}
+	r := newRedactor()
+	r.addSensitiveKeys(data, defaultSensitiveKeys, regexp.MustCompile(`^pin$`))
+
+	for _, tpl := range []string{`{{ toInt .db.password }}`, `{{ toFloat .db.pin }}`, `{{ .db.password | upper | decimalAdd 1 }}`} {
+		var buf strings.Builder
+		err := executeTemplate(&buf, tpl, data, renderConfig{})
+		if err == nil {
+			t.Fatalf("%s: expected error", tpl)
+		}
+		if message := err.Error(); strings.Contains(message, "s3cretpw") || strings.Contains(message, "S3CRETPW") || strings.Contains(message, "x1") {
+			t.Errorf("%s: expected the conversion error not to quote the value, got %s", tpl, message)
+		}
+	}
+
+	tests := []struct{ tpl, value string }{
+		{`{{ .db.password | semverBump "minor" }}`, "s3cretpw"},
+		{`{{ semverCompare .db.password "1.0.0" }}`, "s3cretpw"},
+		{`{{ .db.password | upper | semverBump "minor" }}`, "S3CRETPW"},
+		{`{{ .db.password | title | semverBump "minor" }}`, "S3cretpw"},
+		{`{{ .db.token | base64Encode | semverBump "minor" }}`, "cHc+Pj4/eA=="},
+		{`{{ .db.token | base64URLEncode | semverBump "minor" }}`, "cHc-Pj4_eA=="},
+		{`{{ .db.token | base64RawEncode | semverBump "minor" }}`, "cHc+Pj4/eA"},
+		{`{{ .db.token | urlSafeBase64 | semverBump "minor" }}`, "cHc-Pj4_eA"},
+		{`{{ .db.token | hexEncode | semverBump "minor" }}`, "70773e3e3e3f78"},
+		{`{{ .db.token | hexEncode | upper | semverBump "minor" }}`, "70773E3E3E3F78"},
+		{`{{ .db.pin | semverBump "minor" }}`, "x1"},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		err := executeTemplate(&buf, tt.tpl, data, renderConfig{})
+		if err == nil {
+			t.Fatalf("%s: expected error", tt.tpl)
+		}
+		if !strings.Contains(err.Error(), tt.value) {
+			t.Fatalf("%s: expected the error to contain the value, got %v", tt.tpl, err)
+		}
+		if message := r.redact(err.Error()); strings.Contains(message, tt.value) || !strings.Contains(message, redactedValue) {
+			t.Errorf("%s: expected the value masked, got %s", tt.tpl, message)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// regexpCache compiles every pattern once. A new cache is created for each
+// set of helper functions, so patterns are cached for a single render.
+type regexpCache struct {
+	patterns map[string]*regexp.Regexp
+}
+
+func newRegexpCache() *regexpCache {
+	return &regexpCache{patterns: map[string]*regexp.Regexp{}}
+}
+
+func (c *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := c.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+	}
+	c.patterns[pattern] = re
+	return re, nil
+}
+
+// limitArgs splits the arguments of helpers accepting an optional limit
+// before the string: (s) or (limit, s). The limit defaults to -1 (no limit).
+func limitArgs(name string, args []any) (int, string, error) {
+	switch len(args) {
+	case 1:
+		s, err := toString(args[0])
+		return -1, s, err
+	case 2:
+		limit, err := toInt(args[0])
+		if err != nil {
+			return 0, "", fmt.Errorf("%s: invalid limit: %w", name, err)
+		}
+		s, err := toString(args[1])
+		return limit, s, err
+	default:
+		return 0, "", fmt.Errorf("%s: expected 2 or 3 arguments, got %d", name, len(args)+1)
+	}
+}
+
+func (c *regexpCache) match(pattern, s string) (bool, error) {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+func (c *regexpCache) find(pattern, s string) (string, error) {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(s), nil
+}
+
+// findAll returns all matches, called as (pattern, s) or (pattern, limit, s).
+func (c *regexpCache) findAll(pattern string, args ...any) ([]string, error) {
+	limit, s, err := limitArgs("regexFindAll", args)
+	if err != nil {
+		return nil, err
+	}
+	re, err := c.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := re.FindAllString(s, limit)
+	if result == nil {
+		result = []string{}
+	}
+	return result, nil
+}
+
+// replace replaces all matches, $1 or ${name} in the replacement are
+// expanded to the submatches.
+func (c *regexpCache) replace(pattern, replacement, s string) (string, error) {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// split splits around the matches, called as (pattern, s) or
+// (pattern, limit, s).
+func (c *regexpCache) split(pattern string, args ...any) ([]string, error) {
+	limit, s, err := limitArgs("regexSplit", args)
+	if err != nil {
+		return nil, err
+	}
+	re, err := c.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.Split(s, limit), nil
+}
+
+// submatch returns the groups of the first match in a map. Named groups are
+// stored under their name, unnamed groups under their index. The map is
+// empty when the pattern does not match.
+func (c *regexpCache) submatch(pattern, s string) (map[string]any, error) {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return result, nil
+	}
+
+	for i, name := range re.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		result[name] = match[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexFunctions(t *testing.T) {
+	c := newRegexpCache()
+
+	t.Run("regexMatch", func(t *testing.T) {
+		result, err := c.match(`^\d+$`, "12345")
+		if err != nil || !result {
+			t.Errorf("expected match, got %v, %v", result, err)
+		}
+		result, err = c.match(`^\d+$`, "12a45")
+		if err != nil || result {
+			t.Errorf("expected no match, got %v, %v", result, err)
+		}
+	})
+
+	t.Run("regexFind", func(t *testing.T) {
+		result, err := c.find(`[0-9]+`, "abc 123 def 456")
+		if err != nil || result != "123" {
+			t.Errorf("expected 123, got %v, %v", result, err)
+		}
+	})
+
+	t.Run("regexFindAll", func(t *testing.T) {
+		tests := []struct {
+			args     []any
+			expected []string
+		}{
+			{[]any{"a1 b2 c3"}, []string{"1", "2", "3"}},
+			{[]any{2, "a1 b2 c3"}, []string{"1", "2"}},
+			{[]any{"-1", "a1 b2 c3"}, []string{"1", "2", "3"}},
+			{[]any{"abc"}, []string{}},
+		}
+		for _, tt := range tests {
+			result, err := c.findAll(`[0-9]`, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalValues(result, tt.expected) || result == nil {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		}
+
+		if _, err := c.findAll(`[0-9]`, "x", 1, "a1"); err == nil {
+			t.Errorf("expected error for too many arguments")
+		}
+		if _, err := c.findAll(`[0-9]`, "x", "a1"); err == nil {
+			t.Errorf("expected error for invalid limit")
+		}
+	})
+
+	t.Run("regexReplace", func(t *testing.T) {
+		tests := []struct {
+			pattern, replacement, input, expected string
+		}{
+			{`(\w+)@(\w+)\.com`, "$2:$1", "john@example.com", "example:john"},
+			{`(?P<first>\w+) (?P<last>\w+)`, "${last}, ${first}", "John Doe", "Doe, John"},
+			{`\s+`, " ", "a  b\t\tc", "a b c"},
+		}
+		for _, tt := range tests {
+			result, err := c.replace(tt.pattern, tt.replacement, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		}
+	})
+
+	t.Run("regexSplit", func(t *testing.T) {
+		result, err := c.split(`\s*[,;]\s*`, "a, b;c ,d")
+		if err != nil || !equalValues(result, []string{"a", "b", "c", "d"}) {
+			t.Errorf("unexpected result %v, %v", result, err)
+		}
+		result, err = c.split(`,`, 2, "a,b,c")
+		if err != nil || !equalValues(result, []string{"a", "b,c"}) {
+			t.Errorf("unexpected result %v, %v", result, err)
+		}
+	})
+
+	t.Run("regexSubmatch", func(t *testing.T) {
+		result, err := c.submatch(`(?P<key>\w+)=(\w+)`, "name=john")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result["key"] != "name" || result["2"] != "john" {
+			t.Errorf("unexpected groups: %v", result)
+		}
+
+		result, err = c.submatch(`(?P<key>\w+)=`, "nothing")
+		if err != nil || len(result) != 0 {
+			t.Errorf("expected empty map, got %v, %v", result, err)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		c := newRegexpCache()
+		first, _ := c.compile(`a+`)
+		second, _ := c.compile(`a+`)
+		if first != second || len(c.patterns) != 1 {
+			t.Errorf("expected pattern to be compiled once")
+		}
+	})
+}
+
+func TestRegexInvalidPattern(t *testing.T) {
+	for _, tpl := range []string{
+		`{{ regexMatch "[a-" "abc" }}`,
+		`{{ regexFind "(" "abc" }}`,
+		`{{ regexFindAll "*" "abc" }}`,
+		`{{ regexReplace "a)" "b" "abc" }}`,
+		`{{ regexSplit "[" "abc" }}`,
+		`{{ regexSubmatch "(?P<>a)" "abc" }}`,
+	} {
+		t.Run(tpl, func(t *testing.T) {
+			var buf strings.Builder
+			err := executeTemplate(&buf, tpl, nil, renderConfig{})
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if !strings.Contains(err.Error(), "invalid regular expression") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRegexTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"match", `{{ if regexMatch "^v[0-9]+" .version }}ok{{ end }}`, "ok"},
+		{"replace in pipe", `{{ .version | regexReplace "^v" "" }}`, "1.2.3"},
+		{"submatch", `{{ (regexSubmatch "(?P<major>\\d+)\\.(?P<minor>\\d+)" .version).minor }}`, "2"},
+		{"split and join", `{{ regexSplit "\\." .version | join "-" }}`, "v1-2-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := executeTemplate(&buf, tt.template, map[string]any{"version": "v1.2.3"}, renderConfig{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// renderConfig holds what would make two renders of the same template and
+// data differ: the current time and the source of randomness. The zero value
+// uses the system clock and crypto/rand, fixing them with --now and --seed
+// makes every render reproducible. It also holds how strictly the helpers
+// convert numbers, set with --strict-numbers.
+type renderConfig struct {
+	now           time.Time
+	seed          int64
+	seeded        bool
+	strictNumbers bool
+}
+
+// currentTime returns the time the helpers use as now.
+func (c renderConfig) currentTime() time.Time {
+	if c.now.IsZero() {
+		return time.Now()
+	}
+	return c.now
+}
+
+// random returns a new source of random bytes for a render: crypto/rand, or
+// a ChaCha8 stream derived from the seed, so a seeded render gets the same
+// bytes every time.
+func (c renderConfig) random() io.Reader {
+	if !c.seeded {
+		return rand.Reader
+	}
+	var seed [32]byte
+	binary.LittleEndian.PutUint64(seed[:], uint64(c.seed))
+	return mathrand.NewChaCha8(seed)
+}
+
+// parseNow parses the value of --now, any date parseAny recognizes.
+func parseNow(s string) (time.Time, error) {
+	t, err := parseAny(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --now value: %w", err)
+	}
+	return t, nil
+}
+
+// parseSourceDateEpoch parses the SOURCE_DATE_EPOCH environment variable, the
+// number of seconds since the Unix epoch as in
+// https://reproducible-builds.org/specs/source-date-epoch/.
+func parseSourceDateEpoch(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH value '%s': expected a Unix timestamp", s)
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
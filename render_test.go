@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderConfigCurrentTime(t *testing.T) {
+	fixed := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := (renderConfig{now: fixed}).currentTime(); !got.Equal(fixed) {
+		t.Errorf("expected %v, got %v", fixed, got)
+	}
+
+	before := time.Now()
+	got := renderConfig{}.currentTime()
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("expected the current time, got %v", got)
+	}
+}
+
+func TestRenderConfigRandom(t *testing.T) {
+	read := func(config renderConfig) []byte {
+		buf := make([]byte, 32)
+		if _, err := io.ReadFull(config.random(), buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf
+	}
+
+	seeded := renderConfig{seed: 42, seeded: true}
+	if !bytes.Equal(read(seeded), read(seeded)) {
+		t.Errorf("expected the same bytes for the same seed")
+	}
+	if bytes.Equal(read(seeded), read(renderConfig{seed: 43, seeded: true})) {
+		t.Errorf("expected different bytes for different seeds")
+	}
+	if bytes.Equal(read(renderConfig{}), read(renderConfig{})) {
+		t.Errorf("expected different bytes without a seed")
+	}
+}
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	got, err := parseSourceDateEpoch("1735689600")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(expected) || got.Location() != time.UTC {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	for _, input := range []string{"", "2025-01-01", "1735689600.5", "soon"} {
+		if _, err := parseSourceDateEpoch(input); err == nil {
+			t.Errorf("parseSourceDateEpoch(%q): expected error", input)
+		}
+	}
+}
+
+func TestParseNow(t *testing.T) {
+	expected := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, input := range []string{"2025-01-01T00:00:00Z", "2025-01-01", "1735689600"} {
+		got, err := parseNow(input)
+		if err != nil {
+			t.Fatalf("parseNow(%q): unexpected error: %v", input, err)
+		}
+		if !got.Equal(expected) {
+			t.Errorf("parseNow(%q): expected %v, got %v", input, expected, got)
+		}
+	}
+	if _, err := parseNow("tomorrow"); err == nil || !strings.Contains(err.Error(), "--now") {
+		t.Errorf("expected an error naming --now, got %v", err)
+	}
+}
+
+func TestFixedClockTemplate(t *testing.T) {
+	config := renderConfig{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	data := map[string]any{"created": "2024-12-29T00:00:00Z", "due": "2025-01-01T05:00:00Z"}
+	tpl := `{{ now | formatDate "rfc3339" }} {{ now | year }} {{ durationHuman .created }} {{ durationHuman .due }}`
+
+	for _, execute := range []func(io.Writer, string, any, renderConfig) error{executeTemplate, executeHTMLTemplate} {
+		var buf strings.Builder
+		if err := execute(&buf, tpl, data, config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "2025-01-01T00:00:00Z 2025 3 days ago in 5 hours"; buf.String() != expected {
+			t.Errorf("expected %s, got %s", expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted values are strings in a form resembling the one of SOPS, which
+// can't read them:
+//
+//	ENC[AES256_GCM,data:<base64>,iv:<base64>,salt:<base64>]
+//
+// The data is the AES-256-GCM ciphertext with the tag appended. The key is
+// derived from the passphrase in the key file and the salt with scrypt. No
+// associated data is authenticated, so a value is not bound to its key in
+// the data and decrypts wherever it is moved.
+const (
+	encryptedPrefix = "ENC[AES256_GCM,"
+	encryptedSuffix = "]"
+	saltSize        = 16
+)
+
+// errNoKeyFile is returned when the data has encrypted values but no key
+// file is given.
+var errNoKeyFile = errors.New("the data has encrypted values, but no key file is given (--key-file or TPLSUB_KEY_FILE)")
+
+// secretKey encrypts and decrypts values with a passphrase. Derived keys are
+// cached by salt, as values encrypted together share a salt and scrypt is
+// slow on purpose.
+type secretKey struct {
+	passphrase []byte
+	keys       map[string]cipher.AEAD
+}
+
+func newSecretKey(passphrase []byte) *secretKey {
+	return &secretKey{passphrase: passphrase, keys: map[string]cipher.AEAD{}}
+}
+
+// loadSecretKey reads the passphrase from a key file, without the trailing
+// line break.
+func loadSecretKey(path string) (*secretKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	passphrase := strings.TrimRight(string(content), "\r\n")
+	if passphrase == "" {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+	return newSecretKey([]byte(passphrase)), nil
+}
+
+func (k *secretKey) aead(salt []byte) (cipher.AEAD, error) {
+	if aead, ok := k.keys[string(salt)]; ok {
+		return aead, nil
+	}
+	key, err := scrypt.Key(k.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	k.keys[string(salt)] = aead
+	return aead, nil
+}
+
+// encrypt encrypts a value with the given salt and a random nonce read from
+// random.
+func (k *secretKey) encrypt(plaintext string, salt []byte, random io.Reader) (string, error) {
+	aead, err := k.aead(salt)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(random, iv); err != nil {
+		return "", err
+	}
+	data := aead.Seal(nil, iv, []byte(plaintext), nil)
+
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("%sdata:%s,iv:%s,salt:%s%s", encryptedPrefix, enc(data), enc(iv), enc(salt), encryptedSuffix), nil
+}
+
+func isEncrypted(s string) bool {
+	return strings.HasPrefix(s, encryptedPrefix) && strings.HasSuffix(s, encryptedSuffix)
+}
+
+// decrypt decrypts an ENC[...] value.
+func (k *secretKey) decrypt(value string) (string, error) {
+	fields := map[string][]byte{}
+	body := strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix)
+	for _, field := range strings.Split(body, ",") {
+		name, encoded, ok := strings.Cut(field, ":")
+		if !ok {
+			return "", fmt.Errorf("invalid encrypted value: field '%s' has no value", field)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid encrypted value: field '%s': %w", name, err)
+		}
+		fields[name] = decoded
+	}
+	for _, name := range []string{"data", "iv", "salt"} {
+		if _, ok := fields[name]; !ok {
+			return "", fmt.Errorf("invalid encrypted value: field '%s' is missing", name)
+		}
+	}
+
+	aead, err := k.aead(fields["salt"])
+	if err != nil {
+		return "", err
+	}
+	if len(fields["iv"]) != aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted value: iv must be %d bytes", aead.NonceSize())
+	}
+	plaintext, err := aead.Open(nil, fields["iv"], fields["data"], nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt value: wrong key or corrupted data")
+	}
+	return string(plaintext), nil
+}
+
+// hasEncryptedValues reports whether any string in the data is encrypted.
+func hasEncryptedValues(data any) bool {
+	switch v := data.(type) {
+	case string:
+		return isEncrypted(v)
+	case map[string]any:
+		for _, value := range v {
+			if hasEncryptedValues(value) {
+				return true
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if hasEncryptedValues(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decryptData replaces the encrypted strings in the data with their
+// plaintext, in place, and adds the plaintext to the sensitive values.
+// Errors name the path of the value, never the value.
+func decryptData(data any, key *secretKey, sensitive *redactor) (any, error) {
+	return decryptValue(data, key, sensitive, "")
+}
+
+func decryptValue(data any, key *secretKey, sensitive *redactor, path string) (any, error) {
+	switch v := data.(type) {
+	case string:
+		if !isEncrypted(v) {
+			return v, nil
+		}
+		plaintext, err := key.decrypt(v)
+		if err != nil {
+			if path == "" {
+				path = "."
+			}
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sensitive.add(plaintext)
+		return plaintext, nil
+	case map[string]any:
+		for name, value := range v {
+			decrypted, err := decryptValue(value, key, sensitive, path+"."+name)
+			if err != nil {
+				return nil, err
+			}
+			v[name] = decrypted
+		}
+	case []any:
+		for i, value := range v {
+			decrypted, err := decryptValue(value, key, sensitive, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = decrypted
+		}
+	}
+	return data, nil
+}
+
+// decryptWithKeyFile decrypts the encrypted values of the data with the key
+// in keyFile. The key file is only read when there is something to decrypt.
+func decryptWithKeyFile(data any, keyFile string, sensitive *redactor) (any, error) {
+	if !hasEncryptedValues(data) {
+		return data, nil
+	}
+	if keyFile == "" {
+		return nil, errNoKeyFile
+	}
+	key, err := loadSecretKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return decryptData(data, key, sensitive)
+}
+
+// encryptValues encrypts values for data files. The values of one call share
+// a new random salt, so they are decrypted with a single key derivation.
+func encryptValues(key *secretKey, values []string, random io.Reader) ([]string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	result := make([]string, len(values))
+	for i, value := range values {
+		encrypted, err := key.encrypt(value, salt, random)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt value: %w", err)
+		}
+		result[i] = encrypted
+	}
+	return result, nil
+}
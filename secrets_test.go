@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret.key")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := newSecretKey([]byte("correct horse"))
+	values := []string{"s3cret", "", "Árvíztűrő, tükörfúrógép]", strings.Repeat("x", 1000)}
+
+	encrypted, err := encryptValues(key, values, rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	salt := ""
+	for i, value := range encrypted {
+		if !isEncrypted(value) {
+			t.Errorf("expected an ENC[...] value, got %s", value)
+		}
+		if strings.Contains(value, values[i]) && values[i] != "" {
+			t.Errorf("expected the plaintext not to appear in %s", value)
+		}
+		valueSalt := value[strings.Index(value, ",salt:"):]
+		if salt != "" && valueSalt != salt {
+			t.Errorf("expected the values of one call to share a salt")
+		}
+		salt = valueSalt
+
+		decrypted, err := key.decrypt(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if decrypted != values[i] {
+			t.Errorf("expected %q, got %q", values[i], decrypted)
+		}
+	}
+	if len(key.keys) != 1 {
+		t.Errorf("expected one derived key, got %d", len(key.keys))
+	}
+
+	again, _ := encryptValues(key, values[:1], rand.Reader)
+	if again[0] == encrypted[0] {
+		t.Errorf("expected a new salt and nonce for every call")
+	}
+
+	// A different passphrase must not decrypt the value.
+	if _, err := newSecretKey([]byte("wrong")).decrypt(encrypted[0]); err == nil {
+		t.Errorf("expected error for a wrong key")
+	}
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	key := newSecretKey([]byte("correct horse"))
+	encrypted, _ := encryptValues(key, []string{"s3cret"}, rand.Reader)
+	data := encrypted[0][strings.Index(encrypted[0], "data:")+5 : strings.Index(encrypted[0], ",iv:")]
+
+	tampered := strings.Replace(encrypted[0], data, "A"+data[1:], 1)
+	if data[0] == 'A' {
+		tampered = strings.Replace(encrypted[0], data, "B"+data[1:], 1)
+	}
+
+	invalid := []string{
+		tampered,
+		"ENC[AES256_GCM,data:AAAA,iv:AAAA]",
+		"ENC[AES256_GCM,data:AAAA,iv:AAAA,salt:AAAA]",
+		"ENC[AES256_GCM,data:!!,iv:AAAA,salt:AAAA]",
+		"ENC[AES256_GCM,data]",
+	}
+	for _, value := range invalid {
+		if _, err := key.decrypt(value); err == nil {
+			t.Errorf("decrypt(%s): expected error", value)
+		}
+	}
+}
+
+func TestDecryptData(t *testing.T) {
+	key := newSecretKey([]byte("correct horse"))
+	encrypted, _ := encryptValues(key, []string{"s3cret", "token"}, rand.Reader)
+
+	data := map[string]any{
+		"name": "app",
+		"db":   map[string]any{"password": encrypted[0], "port": 5432.0},
+		"keys": []any{"plain", encrypted[1], map[string]any{"x": "ENC[other]"}},
+	}
+	if !hasEncryptedValues(data) {
+		t.Fatalf("expected encrypted values")
+	}
+
+	result, err := decryptData(data, key, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := result.(map[string]any)
+	if m["db"].(map[string]any)["password"] != "s3cret" || m["keys"].([]any)[1] != "token" {
+		t.Errorf("expected decrypted values, got %v", m)
+	}
+	if m["name"] != "app" || m["keys"].([]any)[0] != "plain" || m["keys"].([]any)[2].(map[string]any)["x"] != "ENC[other]" {
+		t.Errorf("expected other values to be unchanged, got %v", m)
+	}
+	if hasEncryptedValues(result) {
+		t.Errorf("expected no encrypted values left")
+	}
+
+	if result, err := decryptData(encrypted[0], key, nil); err != nil || result != "s3cret" {
+		t.Errorf("expected a top-level value to be decrypted, got %v (%v)", result, err)
+	}
+
+	other := newSecretKey([]byte("wrong"))
+	_, err = decryptData(map[string]any{"list": []any{map[string]any{"secret": encrypted[0]}}}, other, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), ".list[0].secret: ") {
+		t.Errorf("expected an error naming the path, got %v", err)
+	}
+}
+
+func TestDecryptWithKeyFile(t *testing.T) {
+	keyFile := writeKeyFile(t, "correct horse\n")
+	key, err := loadSecretKey(keyFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(key.passphrase) != "correct horse" {
+		t.Errorf("expected the passphrase without the line break, got %q", key.passphrase)
+	}
+	encrypted, _ := encryptValues(key, []string{"s3cret"}, rand.Reader)
+
+	sensitive := newRedactor()
+	result, err := decryptWithKeyFile(map[string]any{"value": encrypted[0]}, keyFile, sensitive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.(map[string]any)["value"] != "s3cret" {
+		t.Errorf("expected the decrypted value, got %v", result)
+	}
+	if message := sensitive.redact("value: s3cret"); message != "value: [REDACTED]" {
+		t.Errorf("expected decrypted values to be sensitive, got %q", message)
+	}
+
+	short, _ := encryptValues(key, []string{"ab"}, rand.Reader)
+	if _, err := decryptWithKeyFile(map[string]any{"value": short[0]}, keyFile, sensitive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message := sensitive.redact("value: ab"); message != "value: [REDACTED]" {
+		t.Errorf("expected short decrypted values to be sensitive, got %q", message)
+	}
+
+	if _, err := decryptWithKeyFile(map[string]any{"password": encrypted[0]}, "", nil); !errors.Is(err, errNoKeyFile) {
+		t.Errorf("expected errNoKeyFile, got %v", err)
+	}
+	if _, err := decryptWithKeyFile(map[string]any{"password": "plain"}, "/nonexistent/key", nil); err != nil {
+		t.Errorf("expected the key file not to be read without encrypted values, got %v", err)
+	}
+	if _, err := decryptWithKeyFile(map[string]any{"password": encrypted[0]}, "/nonexistent/key", nil); err == nil {
+		t.Errorf("expected error for a missing key file")
+	}
+	if _, err := loadSecretKey(writeKeyFile(t, "\n")); err == nil {
+		t.Errorf("expected error for an empty key file")
+	}
+}
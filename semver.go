@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func parseSemver(name string, v any) (*semver.Version, error) {
+	s, _ := toString(v)
+	version, err := semver.NewVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid version '%s': %w", name, s, err)
+	}
+	return version, nil
+}
+
+// semverParse splits a semantic version into its parts. Missing minor and
+// patch numbers are zero and a leading "v" is allowed, so "v1.2" is 1.2.0.
+func semverParse(s string) (map[string]any, error) {
+	version, err := parseSemver("semver", s)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"major":      int(version.Major()),
+		"minor":      int(version.Minor()),
+		"patch":      int(version.Patch()),
+		"prerelease": version.Prerelease(),
+		"build":      version.Metadata(),
+		"version":    version.String(),
+		"original":   version.Original(),
+	}, nil
+}
+
+// semverCompare reports whether a version satisfies a constraint like
+// ">=1.2.0 <2.0.0", "~1.2" or "^1.2.3". Constraints separated by spaces or
+// commas must all match, "||" separates alternatives.
+func semverCompare(constraint string, v any) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("semverCompare: invalid constraint '%s': %w", constraint, err)
+	}
+	version, err := parseSemver("semverCompare", v)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(version), nil
+}
+
+// semverBump increments the "major", "minor" or "patch" part of a version,
+// resetting the parts after it. The pre-release and build metadata are
+// dropped, except that bumping the patch of a pre-release version releases
+// it: 1.2.3-rc.1 becomes 1.2.3. A leading "v" is kept.
+func semverBump(part string, v any) (string, error) {
+	version, err := parseSemver("semverBump", v)
+	if err != nil {
+		return "", err
+	}
+
+	var bumped semver.Version
+	switch part {
+	case "major":
+		bumped = version.IncMajor()
+	case "minor":
+		bumped = version.IncMinor()
+	case "patch":
+		bumped = version.IncPatch()
+	default:
+		return "", fmt.Errorf("semverBump: unknown part '%s', expected major, minor or patch", part)
+	}
+
+	if strings.HasPrefix(version.Original(), "v") {
+		return "v" + bumped.String(), nil
+	}
+	return bumped.String(), nil
+}
+
+// semverSort returns a copy of a list of versions in ascending semantic
+// version order, pre-releases before their release. The versions are
+// returned as they are written in the list.
+func semverSort(v any) ([]any, error) {
+	list, err := toList(v)
+	if err != nil {
+		return nil, fmt.Errorf("semverSort: %w", err)
+	}
+
+	versions := make([]*semver.Version, len(list))
+	for i, item := range list {
+		if versions[i], err = parseSemver("semverSort", item); err != nil {
+			return nil, err
+		}
+	}
+
+	indexes := make([]int, len(list))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return versions[indexes[i]].LessThan(versions[indexes[j]])
+	})
+
+	result := make([]any, len(list))
+	for i, index := range indexes {
+		result[i] = list[index]
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSemverParse(t *testing.T) {
+	result, err := semverParse("v1.2.3-rc.1+build.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"major": 1, "minor": 2, "patch": 3, "prerelease": "rc.1", "build": "build.5",
+		"version": "1.2.3-rc.1+build.5", "original": "v1.2.3-rc.1+build.5",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	result, err = semverParse("2.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["version"] != "2.1.0" || result["patch"] != 0 {
+		t.Errorf("expected 2.1.0, got %v", result)
+	}
+
+	for _, input := range []string{"", "abc", "1.2.3.4", "1.2.x-"} {
+		if _, err := semverParse(input); err == nil {
+			t.Errorf("semverParse(%q): expected error", input)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    any
+		expected   bool
+	}{
+		{">=1.2.0 <2.0.0", "1.5.0", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0, <2.0.0", "v1.2.0", true},
+		{">=1.2.0 <2.0.0", "1.5.0-rc.1", false},
+		{">=1.2.0-0 <2.0.0", "1.5.0-rc.1", true},
+		{"~1.2", "1.2.9", true},
+		{"~1.2", "1.3.0", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"<1.0.0 || >=3.0.0", "3.1.0", true},
+		{"1.x", "1.4.2", true},
+		{"=1.2.3", "1.2.3", true},
+	}
+
+	for _, tt := range tests {
+		result, err := semverCompare(tt.constraint, tt.version)
+		if err != nil {
+			t.Fatalf("semverCompare(%q, %v): unexpected error: %v", tt.constraint, tt.version, err)
+		}
+		if result != tt.expected {
+			t.Errorf("semverCompare(%q, %v): expected %v, got %v", tt.constraint, tt.version, tt.expected, result)
+		}
+	}
+
+	if _, err := semverCompare(">>1", "1.0.0"); err == nil {
+		t.Errorf("expected error for invalid constraint")
+	}
+	if _, err := semverCompare(">=1.0.0", "one"); err == nil {
+		t.Errorf("expected error for invalid version")
+	}
+}
+
+func TestSemverBump(t *testing.T) {
+	tests := []struct {
+		part, version, expected string
+	}{
+		{"major", "1.2.3", "2.0.0"},
+		{"minor", "1.2.3", "1.3.0"},
+		{"patch", "1.2.3", "1.2.4"},
+		{"minor", "v1.2.3", "v1.3.0"},
+		{"patch", "1.2.3-rc.1", "1.2.3"},
+		{"minor", "1.2.3+build.1", "1.3.0"},
+	}
+
+	for _, tt := range tests {
+		result, err := semverBump(tt.part, tt.version)
+		if err != nil {
+			t.Fatalf("semverBump(%q, %q): unexpected error: %v", tt.part, tt.version, err)
+		}
+		if result != tt.expected {
+			t.Errorf("semverBump(%q, %q): expected %s, got %s", tt.part, tt.version, tt.expected, result)
+		}
+	}
+
+	if _, err := semverBump("build", "1.2.3"); err == nil {
+		t.Errorf("expected error for unknown part")
+	}
+	if _, err := semverBump("major", "x"); err == nil {
+		t.Errorf("expected error for invalid version")
+	}
+}
+
+func TestSemverSort(t *testing.T) {
+	result, err := semverSort([]any{"1.10.0", "v1.2.0", "1.2.0-rc.1", "1.9", "1.2.0-alpha"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []any{"1.2.0-alpha", "1.2.0-rc.1", "v1.2.0", "1.9", "1.10.0"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if result, err := semverSort([]string{"2.0.0", "1.0.0"}); err != nil || !reflect.DeepEqual(result, []any{"1.0.0", "2.0.0"}) {
+		t.Errorf("expected sorted string slice, got %v, %v", result, err)
+	}
+	if _, err := semverSort([]any{"1.0.0", "latest"}); err == nil {
+		t.Errorf("expected error for invalid version")
+	}
+	if _, err := semverSort("1.0.0"); err == nil {
+		t.Errorf("expected error for non list")
+	}
+}
+
+func TestSemverTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"version": "v1.4.2", "tags": []any{"v1.10.0", "v1.9.1", "v1.4.2"}}
+	tpl := `{{ (semver .version).major }} {{ semverCompare ">=1.2.0 <2.0.0" .version }} {{ .version | semverBump "minor" }} {{ semverSort .tags | last }}`
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "1 true v1.5.0 v1.10.0"; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
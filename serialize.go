@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func toYAML(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlValue(v)); err != nil {
+		return "", fmt.Errorf("failed to marshal to YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to marshal to YAML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// yamlValue writes json.Number values as YAML numbers, as written in the
+// data. The encoder would write them as quoted strings.
+func yamlValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(val.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: val.String()}
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[k] = yamlValue(item)
+		}
+		return m
+	case []any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			list[i] = yamlValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+func fromYAML(s string) (any, error) {
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return normalizeData(v), nil
+}
+
+// toTOML renders a map as a TOML document. A TOML document is always a
+// table, so other values are rejected.
+func toTOML(v any) (string, error) {
+	if _, ok := v.(map[string]any); !ok {
+		return "", fmt.Errorf("failed to marshal to TOML: expected a map, got %T", v)
+	}
+	converted, err := tomlValue(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal to TOML: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(converted); err != nil {
+		return "", fmt.Errorf("failed to marshal to TOML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// tomlValue turns whole float64 numbers into integers so they are not
+// written as floats like "8080.0", and json.Number values into integers or
+// floats. TOML integers are 64-bit and its floats are float64, so numbers
+// neither holds exactly are an error instead of being rounded or quoted.
+func tomlValue(v any) (any, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
+		if !strings.ContainsAny(val.String(), ".eE") {
+			return nil, errors.New("integer out of the range of TOML integers")
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return nil, errors.New("number out of the range of TOML floats")
+		}
+		if exact, err := toRat(val); err != nil || !ratEqualsFloat(exact, f) {
+			return nil, errors.New("number can't be written exactly as a TOML float")
+		}
+		return f, nil
+	case *big.Int:
+		if !val.IsInt64() {
+			return nil, errors.New("integer out of the range of TOML integers")
+		}
+		return val.Int64(), nil
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return int64(val), nil
+		}
+		return val, nil
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			converted, err := tomlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			converted, err := tomlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	default:
+		return v, nil
+	}
+}
+
+func fromTOML(s string) (any, error) {
+	var v map[string]any
+	if _, err := toml.Decode(s, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	return normalizeData(v), nil
+}
+
+func fromJSON(s string) (any, error) {
+	v, err := decodeJSON([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return v, nil
+}
+
+// normalizeData converts decoded YAML and TOML values to the same shapes
+// the JSON decoder produces, so the collection helpers work on them too.
+func normalizeData(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = normalizeData(item)
+		}
+		return val
+	case map[any]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[fmt.Sprintf("%v", k)] = normalizeData(item)
+		}
+		return m
+	case []any:
+		for i, item := range val {
+			val[i] = normalizeData(item)
+		}
+		return val
+	case []map[string]any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			list[i] = normalizeData(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toINI renders a map as an INI document. Scalar values of the top level map
+// are written before any section, nested maps become sections (deeper levels
+// use dotted section names) and lists are written as repeated keys.
+func toINI(v any) (string, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("failed to marshal to INI: expected a map, got %T", v)
+	}
+
+	var b strings.Builder
+	if err := writeINISection(&b, "", m); err != nil {
+		return "", fmt.Errorf("failed to marshal to INI: %w", err)
+	}
+	return b.String(), nil
+}
+
+func writeINISection(b *strings.Builder, name string, m map[string]any) error {
+	var sections []string
+
+	for _, k := range sortedMapKeys(m) {
+		if err := checkINIKey(k); err != nil {
+			return err
+		}
+	}
+
+	if name != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "[%s]\n", name)
+	}
+
+	for _, k := range sortedMapKeys(m) {
+		switch val := m[k].(type) {
+		case map[string]any:
+			sections = append(sections, k)
+		case []any:
+			for i, item := range val {
+				switch item.(type) {
+				case map[string]any, []any:
+					return fmt.Errorf("unsupported nested value at %s[%d]", k, i)
+				}
+				s, _ := toString(item)
+				fmt.Fprintf(b, "%s = %s\n", k, iniValue(s))
+			}
+		default:
+			s, _ := toString(val)
+			fmt.Fprintf(b, "%s = %s\n", k, iniValue(s))
+		}
+	}
+
+	for _, k := range sections {
+		sectionName := k
+		if name != "" {
+			sectionName = name + "." + k
+		}
+		if err := writeINISection(b, sectionName, m[k].(map[string]any)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkINIKey rejects the keys that would change the structure of the
+// document: a key with "=" or a line break would add another key, and one
+// with brackets another section.
+func checkINIKey(k string) error {
+	if k == "" || strings.ContainsAny(k, "=[]\r\n") || strings.TrimSpace(k) != k || strings.HasPrefix(k, ";") || strings.HasPrefix(k, "#") {
+		return fmt.Errorf("invalid key %q", k)
+	}
+	return nil
+}
+
+// iniValue quotes a value that would not be read back as written: one with
+// a line break, a comment character, a quote or surrounding spaces. Quoted
+// values escape backslashes, quotes and line breaks.
+func iniValue(s string) string {
+	if !strings.ContainsAny(s, "\r\n;#\"") && strings.TrimSpace(s) == s {
+		return s
+	}
+	return `"` + iniEscaper.Replace(s) + `"`
+}
+
+var iniEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// toXML renders a value as an indented XML document with the given root
+// element name. Map keys become child elements, lists repeat the element of
+// their key (or use "item" elements directly below the root).
+func toXML(root string, v any) (string, error) {
+	var b strings.Builder
+	if err := writeXMLElement(&b, root, v, 0); err != nil {
+		return "", fmt.Errorf("failed to marshal to XML: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func writeXMLElement(b *strings.Builder, name string, v any, depth int) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("invalid element name '%s'", name)
+	}
+
+	indent := strings.Repeat("  ", depth)
+
+	switch val := v.(type) {
+	case nil:
+		fmt.Fprintf(b, "%s<%s/>\n", indent, name)
+	case map[string]any:
+		fmt.Fprintf(b, "%s<%s>\n", indent, name)
+		for _, k := range sortedMapKeys(val) {
+			if list, ok := val[k].([]any); ok {
+				for _, item := range list {
+					if err := writeXMLElement(b, k, item, depth+1); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := writeXMLElement(b, k, val[k], depth+1); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(b, "%s</%s>\n", indent, name)
+	case []any:
+		fmt.Fprintf(b, "%s<%s>\n", indent, name)
+		for _, item := range val {
+			if err := writeXMLElement(b, "item", item, depth+1); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(b, "%s</%s>\n", indent, name)
+	default:
+		s, _ := toString(val)
+		fmt.Fprintf(b, "%s<%s>", indent, name)
+		if err := xml.EscapeText(b, []byte(s)); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "</%s>\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSerializationFunctions(t *testing.T) {
+	data := map[string]any{
+		"name": "app",
+		"port": 8080.0,
+		"tags": []any{"a", "b"},
+		"db": map[string]any{
+			"host": "localhost",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		fn       func(any) (string, error)
+		expected string
+	}{
+		{
+			name:     "toYAML",
+			fn:       toYAML,
+			expected: "db:\n  host: localhost\nname: app\nport: 8080\ntags:\n  - a\n  - b\n",
+		},
+		{
+			name:     "toTOML",
+			fn:       toTOML,
+			expected: "name = \"app\"\nport = 8080\ntags = [\"a\", \"b\"]\n\n[db]\n  host = \"localhost\"\n",
+		},
+		{
+			name:     "toINI",
+			fn:       toINI,
+			expected: "name = app\nport = 8080\ntags = a\ntags = b\n\n[db]\nhost = localhost\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Render multiple times to make sure the key order is stable
+			for range 5 {
+				result, err := tt.fn(data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result != tt.expected {
+					t.Fatalf("expected %q, got %q", tt.expected, result)
+				}
+			}
+		})
+	}
+
+	t.Run("json.Number", func(t *testing.T) {
+		numbers := map[string]any{"id": json.Number("9007199254740993"), "price": json.Number("1.50")}
+		result, err := toYAML(numbers)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "id: 9007199254740993\nprice: 1.50\n"; result != expected {
+			t.Errorf("toYAML: expected %q, got %q", expected, result)
+		}
+		result, err = toTOML(numbers)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "id = 9007199254740993\nprice = 1.5\n"; result != expected {
+			t.Errorf("toTOML: expected %q, got %q", expected, result)
+		}
+
+		big64, _ := new(big.Int).SetString("12345678901234567890", 10)
+		for _, v := range []any{json.Number("12345678901234567890"), json.Number("100000000000000000000"), big64, json.Number("1e400"), json.Number("0.1000000000000000000001")} {
+			if result, err := toTOML(map[string]any{"id": v}); err == nil {
+				t.Errorf("toTOML(%v): expected error for a number TOML can't hold, got %q", v, result)
+			}
+		}
+	})
+
+	t.Run("toINI hostile values", func(t *testing.T) {
+		result, err := toINI(map[string]any{
+			"a": "x\ny = injected",
+			"b": "\n[section]",
+			"c": " padded ; not a comment",
+			"d": `say "hi" \o/`,
+			"e": "plain value",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "a = \"x\\ny = injected\"\nb = \"\\n[section]\"\nc = \" padded ; not a comment\"\nd = \"say \\\"hi\\\" \\\\o/\"\ne = plain value\n"
+		if result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("toINI hostile keys", func(t *testing.T) {
+		for _, key := range []string{"a=b", "[section]", "a\nb", "", " a", "; comment"} {
+			if _, err := toINI(map[string]any{key: "x"}); err == nil {
+				t.Errorf("expected error for key %q", key)
+			}
+			if _, err := toINI(map[string]any{"s": map[string]any{key: "x"}}); err == nil {
+				t.Errorf("expected error for key %q in a section", key)
+			}
+		}
+		if _, err := toINI(map[string]any{"a]\n[b": map[string]any{"k": "v"}}); err == nil {
+			t.Errorf("expected error for a hostile section name")
+		}
+	})
+
+	t.Run("toTOML non map", func(t *testing.T) {
+		for _, v := range []any{[]any{1, 2}, "text", 42} {
+			if _, err := toTOML(v); err == nil {
+				t.Errorf("expected error for %T", v)
+			}
+		}
+	})
+
+	t.Run("toINI non map", func(t *testing.T) {
+		if _, err := toINI([]any{1, 2}); err == nil {
+			t.Errorf("expected error for non map value")
+		}
+	})
+}
+
+func TestToXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		data     any
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "map",
+			root:     "config",
+			data:     map[string]any{"b": "<&>", "a": 1.0, "list": []any{"x", "y"}, "empty": nil},
+			expected: "<config>\n  <a>1</a>\n  <b>&lt;&amp;&gt;</b>\n  <empty/>\n  <list>x</list>\n  <list>y</list>\n</config>",
+		},
+		{
+			name:     "root list",
+			root:     "items",
+			data:     []any{"a", "b"},
+			expected: "<items>\n  <item>a</item>\n  <item>b</item>\n</items>",
+		},
+		{
+			name:     "invalid element name",
+			root:     "config",
+			data:     map[string]any{"1st": "a"},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toXML(tt.root, tt.data)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "fromJSON",
+			template: `{{ $d := fromJSON "{\"a\": [1, 2]}" }}{{ len $d.a }}`,
+			expected: "2",
+		},
+		{
+			name:     "fromYAML",
+			template: `{{ $d := fromYAML "a:\n  b: [x, y]" }}{{ first $d.a.b }}`,
+			expected: "x",
+		},
+		{
+			name:     "fromTOML array of tables",
+			template: `{{ $d := fromTOML "[[srv]]\nname = \"a\"\n[[srv]]\nname = \"b\"" }}{{ (last $d.srv).name }}`,
+			expected: "b",
+		},
+		{
+			name:     "round trip",
+			template: `{{ (. | toYAML | fromYAML | toTOML | fromTOML | toJSON | fromJSON).name }}`,
+			expected: "app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := executeTemplate(&buf, tt.template, map[string]any{"name": "app"}, renderConfig{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+
+	for _, name := range []string{"fromJSON", "fromYAML", "fromTOML"} {
+		t.Run(name+" invalid", func(t *testing.T) {
+			var buf strings.Builder
+			err := executeTemplate(&buf, `{{ `+name+` "a: [" }}`, nil, renderConfig{})
+			if err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// tableColumn describes a column of a table: the path of the value in the
+// rows of maps, the header and the alignment ("left", "right", "center" or
+// "auto", which right aligns columns holding only numbers).
+type tableColumn struct {
+	path   string
+	header string
+	align  string
+}
+
+var tableStyles = map[string]bool{"ascii": true, "box": true, "markdown": true, "tsv": true, "plain": true}
+
+// parseTableColumn parses a column spec, either a string "path" or
+// "path:Header", or a map with "path", "header" and "align" keys.
+func parseTableColumn(spec any) (tableColumn, error) {
+	switch s := spec.(type) {
+	case string:
+		path, header, found := strings.Cut(s, ":")
+		if !found {
+			header = path
+		}
+		return tableColumn{path: path, header: header, align: "auto"}, nil
+	case map[string]any:
+		column := tableColumn{align: "auto"}
+		column.path, _ = toString(s["path"])
+		column.header = column.path
+		if header, ok := s["header"]; ok {
+			column.header, _ = toString(header)
+		}
+		if align, ok := s["align"]; ok {
+			column.align, _ = toString(align)
+		}
+		switch column.align {
+		case "auto", "left", "right", "center":
+		default:
+			return column, fmt.Errorf("unknown alignment '%s'", column.align)
+		}
+		return column, nil
+	default:
+		return tableColumn{}, fmt.Errorf("column must be a string or a map, got %T", spec)
+	}
+}
+
+// tableColumns returns the columns of the table. Without a column spec the
+// columns of rows of maps are the keys of all the rows in sorted order.
+func tableColumns(specs []any, rows []any) ([]tableColumn, error) {
+	columns := []tableColumn{}
+	if specs != nil {
+		for _, spec := range specs {
+			column, err := parseTableColumn(spec)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, column)
+		}
+		return columns, nil
+	}
+
+	seen := map[string]any{}
+	for _, row := range rows {
+		if m, ok := row.(map[string]any); ok {
+			for k := range m {
+				seen[k] = true
+			}
+		}
+	}
+	for _, k := range sortedMapKeys(seen) {
+		columns = append(columns, tableColumn{path: k, header: k, align: "auto"})
+	}
+	return columns, nil
+}
+
+// tableCell formats a value for a table cell. Line breaks are replaced with
+// spaces so every row stays on a single line.
+func tableCell(v any) string {
+	if v == nil {
+		return ""
+	}
+	s, _ := toString(v)
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(s)
+}
+
+func isNumberValue(v any) bool {
+	switch v.(type) {
+	case int, int64, float64, json.Number:
+		return true
+	default:
+		return false
+	}
+}
+
+// table renders a list of maps or a list of lists as a text table. The last
+// argument is the list of rows, the arguments before it are options: a style
+// ("ascii", the default, "box", "markdown", "tsv" or "plain") and a list of
+// column specs. For rows of maps a column spec is a path, optionally followed
+// by a header ("name:Name"), or a map with "path", "header" and "align" keys.
+// For rows of lists the column specs are matched to the values by position
+// and give the headers; rows of lists without column specs have no header.
+// Columns are aligned by display width and columns holding only numbers are
+// right aligned.
+func table(args ...any) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("table: expected at least 1 argument")
+	}
+
+	rows, err := toList(args[len(args)-1])
+	if err != nil {
+		return "", fmt.Errorf("table: %w", err)
+	}
+
+	style := "ascii"
+	var specs []any
+	for _, option := range args[:len(args)-1] {
+		if s, ok := option.(string); ok {
+			if !tableStyles[s] {
+				return "", fmt.Errorf("table: unknown style '%s'", s)
+			}
+			style = s
+			continue
+		}
+		if specs, err = toList(option); err != nil {
+			return "", fmt.Errorf("table: invalid columns: %w", err)
+		}
+	}
+
+	columns, err := tableColumns(specs, rows)
+	if err != nil {
+		return "", fmt.Errorf("table: invalid columns: %w", err)
+	}
+
+	cells := make([][]string, len(rows))
+	numeric := make([]bool, len(columns))
+	for i := range numeric {
+		numeric[i] = len(rows) > 0
+	}
+	for i, row := range rows {
+		var values []any
+		switch r := row.(type) {
+		case map[string]any:
+			for _, column := range columns {
+				v, _ := lookupPath(r, column.path)
+				values = append(values, v)
+			}
+		default:
+			list, err := toList(row)
+			if err != nil {
+				return "", fmt.Errorf("table: row %d must be a map or a list, got %T", i, row)
+			}
+			if specs == nil {
+				for len(columns) < len(list) {
+					columns = append(columns, tableColumn{align: "auto"})
+					numeric = append(numeric, true)
+				}
+			}
+			for j := range columns {
+				if j < len(list) {
+					values = append(values, list[j])
+				} else {
+					values = append(values, nil)
+				}
+			}
+		}
+
+		cells[i] = make([]string, len(values))
+		for j, v := range values {
+			cells[i][j] = tableCell(v)
+			if v != nil && !isNumberValue(v) {
+				numeric[j] = false
+			}
+		}
+	}
+
+	// Without columns there is nothing to draw, not even borders.
+	if len(columns) == 0 {
+		return "", nil
+	}
+
+	for i, row := range cells {
+		for len(row) < len(columns) {
+			row = append(row, "")
+		}
+		cells[i] = row
+	}
+
+	header := specs != nil || len(rows) == 0 || isMapRows(rows)
+	headers := make([]string, len(columns))
+	widths := make([]int, len(columns))
+	aligns := make([]string, len(columns))
+	for j, column := range columns {
+		headers[j] = tableCell(column.header)
+		if header {
+			widths[j] = width(headers[j])
+		}
+		aligns[j] = column.align
+		if aligns[j] == "auto" {
+			aligns[j] = "left"
+			if numeric[j] {
+				aligns[j] = "right"
+			}
+		}
+	}
+	for _, row := range cells {
+		for j, cell := range row {
+			if style == "markdown" {
+				row[j] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			widths[j] = max(widths[j], width(row[j]))
+		}
+	}
+	if style == "markdown" {
+		for j := range headers {
+			headers[j] = strings.ReplaceAll(headers[j], "|", `\|`)
+			widths[j] = max(widths[j], width(headers[j]), 3)
+		}
+	}
+
+	var b strings.Builder
+	renderTable(&b, style, header, headers, cells, widths, aligns)
+	return b.String(), nil
+}
+
+func isMapRows(rows []any) bool {
+	for _, row := range rows {
+		if _, ok := row.(map[string]any); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// alignCell pads a cell to the column width.
+func alignCell(s string, n int, align string) string {
+	var result string
+	switch align {
+	case "right":
+		result, _ = padLeft(n, s)
+	case "center":
+		result, _ = center(n, s)
+	default:
+		result, _ = padRight(n, s)
+	}
+	return result
+}
+
+func renderTable(b *strings.Builder, style string, header bool, headers []string, cells [][]string, widths []int, aligns []string) {
+	line := func(cells []string, left, sep, right string) {
+		parts := make([]string, len(cells))
+		for j, cell := range cells {
+			parts[j] = alignCell(cell, widths[j], aligns[j])
+		}
+		b.WriteString(strings.TrimRight(left+strings.Join(parts, sep)+right, " "))
+		b.WriteByte('\n')
+	}
+	rule := func(fill, left, sep, right string) {
+		parts := make([]string, len(widths))
+		for j, w := range widths {
+			parts[j] = strings.Repeat(fill, w+2)
+		}
+		b.WriteString(left + strings.Join(parts, sep) + right)
+		b.WriteByte('\n')
+	}
+
+	switch style {
+	case "tsv":
+		if header {
+			b.WriteString(strings.Join(headers, "\t"))
+			b.WriteByte('\n')
+		}
+		for _, row := range cells {
+			b.WriteString(strings.Join(row, "\t"))
+			b.WriteByte('\n')
+		}
+	case "plain":
+		if header {
+			line(headers, "", "  ", "")
+		}
+		for _, row := range cells {
+			line(row, "", "  ", "")
+		}
+	case "markdown":
+		line(headers, "| ", " | ", " |")
+		parts := make([]string, len(widths))
+		for j, w := range widths {
+			switch aligns[j] {
+			case "right":
+				parts[j] = strings.Repeat("-", w-1) + ":"
+			case "center":
+				parts[j] = ":" + strings.Repeat("-", w-2) + ":"
+			default:
+				parts[j] = strings.Repeat("-", w)
+			}
+		}
+		b.WriteString("| " + strings.Join(parts, " | ") + " |\n")
+		for _, row := range cells {
+			line(row, "| ", " | ", " |")
+		}
+	case "box":
+		rule("─", "┌", "┬", "┐")
+		if header {
+			line(headers, "│ ", " │ ", " │")
+			if len(cells) > 0 {
+				rule("─", "├", "┼", "┤")
+			}
+		}
+		for _, row := range cells {
+			line(row, "│ ", " │ ", " │")
+		}
+		rule("─", "└", "┴", "┘")
+	default:
+		rule("-", "+", "+", "+")
+		if header {
+			line(headers, "| ", " | ", " |")
+			if len(cells) > 0 {
+				rule("-", "+", "+", "+")
+			}
+		}
+		for _, row := range cells {
+			line(row, "| ", " | ", " |")
+		}
+		rule("-", "+", "+", "+")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	rows := []any{
+		map[string]any{"name": "Ádám", "age": 30, "city": "Budapest"},
+		map[string]any{"name": "日本", "age": 7.0, "city": "Tokyo|JP"},
+		map[string]any{"name": "Zsófia", "age": 102.5},
+	}
+	columns := []any{"name:Name", "age:Age", "city"}
+
+	tests := []struct {
+		name     string
+		args     []any
+		expected string
+	}{
+		{
+			name: "ascii",
+			args: []any{columns, rows},
+			expected: `+--------+-------+----------+
+| Name   |   Age | city     |
++--------+-------+----------+
+| Ádám   |    30 | Budapest |
+| 日本   |     7 | Tokyo|JP |
+| Zsófia | 102.5 |          |
++--------+-------+----------+
+`,
+		},
+		{
+			name: "box",
+			args: []any{"box", []any{"name", "age"}, rows[:2]},
+			expected: `┌──────┬─────┐
+│ name │ age │
+├──────┼─────┤
+│ Ádám │  30 │
+│ 日本 │   7 │
+└──────┴─────┘
+`,
+		},
+		{
+			name: "markdown",
+			args: []any{"markdown", columns, rows},
+			expected: `| Name   |   Age | city      |
+| ------ | ----: | --------- |
+| Ádám   |    30 | Budapest  |
+| 日本   |     7 | Tokyo\|JP |
+| Zsófia | 102.5 |           |
+`,
+		},
+		{
+			name:     "tsv",
+			args:     []any{"tsv", columns, rows},
+			expected: "Name\tAge\tcity\nÁdám\t30\tBudapest\n日本\t7\tTokyo|JP\nZsófia\t102.5\t\n",
+		},
+		{
+			name:     "plain",
+			args:     []any{"plain", columns, rows},
+			expected: "Name      Age  city\nÁdám       30  Budapest\n日本        7  Tokyo|JP\nZsófia  102.5\n",
+		},
+		{
+			name:     "columns from keys",
+			args:     []any{"plain", rows[:1]},
+			expected: "age  city      name\n 30  Budapest  Ádám\n",
+		},
+		{
+			name: "column alignment",
+			args: []any{"plain", []any{
+				map[string]any{"path": "name", "align": "right"},
+				map[string]any{"path": "age", "header": "Years", "align": "left"},
+			}, rows[:2]},
+			expected: "name  Years\nÁdám  30\n日本  7\n",
+		},
+		{
+			name: "rows of lists",
+			args: []any{[]any{[]any{"a", 1}, []any{"bb", 22, "x"}}},
+			expected: `+----+----+---+
+| a  |  1 |   |
+| bb | 22 | x |
++----+----+---+
+`,
+		},
+		{
+			name:     "rows of lists with headers",
+			args:     []any{"tsv", []any{"key", "value"}, [][]string{{"a", "1"}, {"b", "2"}}},
+			expected: "key\tvalue\na\t1\nb\t2\n",
+		},
+		{
+			name:     "multi line cells",
+			args:     []any{"plain", []any{"text"}, []any{map[string]any{"text": "a\nb"}}},
+			expected: "text\na b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := table(tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTableEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []any
+		expected string
+	}{
+		{"no rows", []any{[]any{}}, ""},
+		{"nil rows", []any{nil}, ""},
+		{"empty rows", []any{[]any{[]any{}, map[string]any{}}}, ""},
+		{"styled no rows", []any{"box", []any{}}, ""},
+		{"columns only", []any{[]any{"name:Name", "age"}, []any{}}, "+------+-----+\n| Name | age |\n+------+-----+\n"},
+		{"box columns only", []any{"box", []any{"name"}, []any{}}, "┌──────┐\n│ name │\n└──────┘\n"},
+		{"markdown columns only", []any{"markdown", []any{"name"}, []any{}}, "| name |\n| ---- |\n"},
+		{"tsv columns only", []any{"tsv", []any{"name", "age"}, []any{}}, "name\tage\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := table(tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTableErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+	}{
+		{"no arguments", nil},
+		{"not a list", []any{"rows"}},
+		{"unknown style", []any{"html", []any{}}},
+		{"invalid row", []any{[]any{"a"}}},
+		{"invalid column", []any{[]any{1}, []any{}}},
+		{"invalid alignment", []any{[]any{map[string]any{"path": "a", "align": "middle"}}, []any{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := table(tt.args...); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestTableTemplate(t *testing.T) {
+	var buf strings.Builder
+	data := map[string]any{"items": []any{
+		map[string]any{"name": "apple", "price": 1.5},
+		map[string]any{"name": "banana", "price": 12},
+	}}
+	if err := executeTemplate(&buf, `{{ table "markdown" (list "name" "price") .items }}`, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "| name   | price |\n| ------ | ----: |\n| apple  |   1.5 |\n| banana |    12 |\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	return u, nil
+}
+
+// urlParse splits a URL into its parts. URLs without a slash after the
+// scheme, like "mailto:john@example.com", have an "opaque" part instead of a
+// host and a path. The query is a map of lists, as a parameter can be
+// repeated: {{ index (urlParse .url).query.page 0 }}.
+func urlParse(s string) (map[string]any, error) {
+	u, err := parseURL(s)
+	if err != nil {
+		return nil, err
+	}
+
+	query := map[string]any{}
+	for key, values := range u.Query() {
+		list := make([]any, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		query[key] = list
+	}
+
+	password, _ := u.User.Password()
+	return map[string]any{
+		"scheme":   u.Scheme,
+		"opaque":   u.Opaque,
+		"user":     u.User.Username(),
+		"password": password,
+		"host":     u.Host,
+		"hostname": u.Hostname(),
+		"port":     u.Port(),
+		"path":     u.Path,
+		"rawQuery": u.RawQuery,
+		"query":    query,
+		"fragment": u.Fragment,
+	}, nil
+}
+
+// urlJoin appends path elements to a URL, with exactly one slash between
+// them. ".." and "." elements are resolved.
+func urlJoin(base string, elems ...any) (string, error) {
+	u, err := parseURL(base)
+	if err != nil {
+		return "", err
+	}
+	paths := make([]string, len(elems))
+	for i, elem := range elems {
+		paths[i], _ = toString(elem)
+	}
+	return u.JoinPath(paths...).String(), nil
+}
+
+// queryValues converts a map of query parameters to url.Values. A value is
+// either a scalar or a list for repeated parameters, nil values are left
+// out.
+func queryValues(v any) (url.Values, error) {
+	values := url.Values{}
+	if v == nil {
+		return values, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("query must be a map, got %T", v)
+	}
+	for key, value := range m {
+		if err := setQueryValue(values, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func setQueryValue(values url.Values, key string, value any) error {
+	values.Del(key)
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return fmt.Errorf("query parameter '%s' must be a scalar or a list, got a map", key)
+	case string:
+		values.Set(key, v)
+		return nil
+	}
+
+	if list, err := toList(value); err == nil {
+		for _, item := range list {
+			s, _ := toString(item)
+			values.Add(key, s)
+		}
+		return nil
+	}
+	s, _ := toString(value)
+	values.Set(key, s)
+	return nil
+}
+
+// urlBuild builds a URL from a map with the same keys urlParse returns. The
+// host is given as "host" or as "hostname" and "port", which take precedence,
+// and the query as "rawQuery" or as "query", a map of scalars or lists, which
+// takes precedence.
+func urlBuild(m map[string]any) (string, error) {
+	str := func(key string) string {
+		s, _ := toString(m[key])
+		return s
+	}
+
+	u := &url.URL{
+		Scheme:   str("scheme"),
+		Opaque:   str("opaque"),
+		Host:     str("host"),
+		Path:     str("path"),
+		RawQuery: str("rawQuery"),
+		Fragment: str("fragment"),
+	}
+	if hostname := str("hostname"); hostname != "" {
+		// IPv6 addresses are written in brackets, with or without a port.
+		u.Host = hostname
+		if strings.Contains(hostname, ":") && !strings.HasPrefix(hostname, "[") {
+			u.Host = "[" + hostname + "]"
+		}
+		if port := str("port"); port != "" {
+			u.Host = net.JoinHostPort(hostname, port)
+		}
+	}
+	if user := str("user"); user != "" {
+		if password := str("password"); password != "" {
+			u.User = url.UserPassword(user, password)
+		} else {
+			u.User = url.User(user)
+		}
+	}
+	if query, ok := m["query"]; ok {
+		values, err := queryValues(query)
+		if err != nil {
+			return "", fmt.Errorf("urlBuild: %w", err)
+		}
+		u.RawQuery = values.Encode()
+	}
+	return u.String(), nil
+}
+
+// urlQuerySet sets a query parameter of a URL, replacing its current values.
+// A list value sets a repeated parameter, nil removes the parameter. The
+// parameters are written in sorted order.
+func urlQuerySet(key string, value any, s string) (string, error) {
+	u, err := parseURL(s)
+	if err != nil {
+		return "", err
+	}
+	values := u.Query()
+	if err := setQueryValue(values, key, value); err != nil {
+		return "", fmt.Errorf("urlQuerySet: %w", err)
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// displayWidth measures the terminal width of strings: East Asian wide
+// characters and emoji take two columns, combining marks none. Ambiguous
+// width characters are always narrow, regardless of the locale, so the
+// output does not depend on the environment.
+var displayWidth = &runewidth.Condition{EastAsianWidth: false, StrictEmojiNeutral: true}
+
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func width(s string) int {
+	return displayWidth.StringWidth(s)
+}
+
+// lenOf returns the length of a list, map or string. Strings are measured in
+// bytes, or in runes or display columns if mode is "runes" or "width".
+func lenOf(args ...any) (int, error) {
+	var mode string
+	var v any
+
+	switch len(args) {
+	case 1:
+		mode, v = "bytes", args[0]
+	case 2:
+		m, ok := args[0].(string)
+		if !ok {
+			return 0, fmt.Errorf("len: mode must be a string, got %T", args[0])
+		}
+		mode, v = m, args[1]
+	default:
+		return 0, fmt.Errorf("len: expected 1 or 2 arguments, got %d", len(args))
+	}
+
+	switch val := v.(type) {
+	case []any:
+		return len(val), nil
+	case map[string]any:
+		return len(val), nil
+	case string:
+		switch mode {
+		case "bytes":
+			return len(val), nil
+		case "runes":
+			return runeLen(val), nil
+		case "width":
+			return width(val), nil
+		default:
+			return 0, fmt.Errorf("len: unknown mode '%s'", mode)
+		}
+	default:
+		return 0, nil
+	}
+}
+
+// optionalString splits the arguments of helpers accepting an optional
+// string before the value: (s) or (option, s).
+func optionalString(name string, defaultVal string, args []any) (string, string, error) {
+	switch len(args) {
+	case 1:
+		s, _ := toString(args[0])
+		return defaultVal, s, nil
+	case 2:
+		option, _ := toString(args[0])
+		s, _ := toString(args[1])
+		return option, s, nil
+	default:
+		return "", "", fmt.Errorf("%s: expected 2 or 3 arguments, got %d", name, len(args)+1)
+	}
+}
+
+// truncate shortens a string to at most n display columns, including the
+// ellipsis. Called as (n, s) or (n, ellipsis, s), the ellipsis defaults to
+// "…".
+func truncate(n int, args ...any) (string, error) {
+	ellipsis, s, err := optionalString("truncate", "…", args)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", fmt.Errorf("truncate: length must not be negative, got %d", n)
+	}
+	if width(s) <= n {
+		return s, nil
+	}
+	if width(ellipsis) > n {
+		ellipsis = ""
+	}
+	return displayWidth.Truncate(s, n, ellipsis), nil
+}
+
+// padding parses the arguments of the padding helpers, (s) or (padChar, s),
+// and returns the pad character, the string and the number of missing
+// columns.
+func padding(name string, n int, args []any) (string, string, int, error) {
+	pad, s, err := optionalString(name, " ", args)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if width(pad) != 1 {
+		return "", "", 0, fmt.Errorf("%s: padding must be a single column wide character, got '%s'", name, pad)
+	}
+	return pad, s, max(0, n-width(s)), nil
+}
+
+// padLeft pads a string on the left to n display columns. Called as (n, s)
+// or (n, padChar, s), padding with spaces by default.
+func padLeft(n int, args ...any) (string, error) {
+	pad, s, missing, err := padding("padLeft", n, args)
+	if err != nil {
+		return "", err
+	}
+	return strings.Repeat(pad, missing) + s, nil
+}
+
+// padRight pads a string on the right to n display columns.
+func padRight(n int, args ...any) (string, error) {
+	pad, s, missing, err := padding("padRight", n, args)
+	if err != nil {
+		return "", err
+	}
+	return s + strings.Repeat(pad, missing), nil
+}
+
+// center pads a string on both sides to n display columns. When the padding
+// can't be split evenly the extra column goes to the right.
+func center(n int, args ...any) (string, error) {
+	pad, s, missing, err := padding("center", n, args)
+	if err != nil {
+		return "", err
+	}
+	return strings.Repeat(pad, missing/2) + s + strings.Repeat(pad, missing-missing/2), nil
+}
+
+// substr returns the runes of s from start up to, but not including, end.
+// Negative positions count from the end of the string, positions out of
+// range are clamped.
+func substr(start, end int, s string) string {
+	runes := []rune(s)
+	clamp := func(i int) int {
+		if i < 0 {
+			i += len(runes)
+		}
+		return max(0, min(i, len(runes)))
+	}
+
+	start, end = clamp(start), clamp(end)
+	if start >= end {
+		return ""
+	}
+	return string(runes[start:end])
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuneLenAndWidth(t *testing.T) {
+	tests := []struct {
+		input           string
+		bytes, runes    int
+		expectedColumns int
+	}{
+		{"hello", 5, 5, 5},
+		{"Árvíztűrő", 13, 9, 9},
+		{"日本語", 9, 3, 6},
+		{"é", 3, 2, 1},
+		{"👍", 4, 1, 2},
+		{"👨‍👩‍👧", 18, 5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got, _ := lenOf(tt.input); got != tt.bytes {
+				t.Errorf("len: expected %d, got %d", tt.bytes, got)
+			}
+			if got := runeLen(tt.input); got != tt.runes {
+				t.Errorf("runeLen: expected %d, got %d", tt.runes, got)
+			}
+			if got, _ := lenOf("runes", tt.input); got != tt.runes {
+				t.Errorf("len runes: expected %d, got %d", tt.runes, got)
+			}
+			if got := width(tt.input); got != tt.expectedColumns {
+				t.Errorf("width: expected %d, got %d", tt.expectedColumns, got)
+			}
+			if got, _ := lenOf("width", tt.input); got != tt.expectedColumns {
+				t.Errorf("len width: expected %d, got %d", tt.expectedColumns, got)
+			}
+		})
+	}
+
+	t.Run("len errors", func(t *testing.T) {
+		if _, err := lenOf("chars", "abc"); err == nil {
+			t.Errorf("expected error for unknown mode")
+		}
+		if _, err := lenOf(1, "abc"); err == nil {
+			t.Errorf("expected error for non string mode")
+		}
+		if got, err := lenOf("runes", []any{1, 2}); err != nil || got != 2 {
+			t.Errorf("expected list length 2, got %d, %v", got, err)
+		}
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		args     []any
+		expected string
+	}{
+		{"short enough", 10, []any{"hello"}, "hello"},
+		{"default ellipsis", 5, []any{"hello world"}, "hell…"},
+		{"custom ellipsis", 8, []any{"...", "hello world"}, "hello..."},
+		{"no ellipsis", 5, []any{"", "hello world"}, "hello"},
+		{"accents", 4, []any{"Árvíztűrő"}, "Árv…"},
+		{"wide characters", 5, []any{"日本語テキスト"}, "日本…"},
+		{"ellipsis wider than length", 2, []any{"...", "hello"}, "he"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := truncate(tt.n, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := truncate(-1, "abc"); err == nil {
+		t.Errorf("expected error for negative length")
+	}
+}
+
+func TestPadding(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(int, ...any) (string, error)
+		n        int
+		args     []any
+		expected string
+	}{
+		{"padLeft", padLeft, 6, []any{"abc"}, "   abc"},
+		{"padLeft char", padLeft, 6, []any{"0", "42"}, "000042"},
+		{"padLeft longer", padLeft, 2, []any{"abc"}, "abc"},
+		{"padRight", padRight, 6, []any{"Ádám"}, "Ádám  "},
+		{"padRight wide", padRight, 6, []any{"日本"}, "日本  "},
+		{"center", center, 7, []any{"abc"}, "  abc  "},
+		{"center uneven", center, 6, []any{"-", "abc"}, "-abc--"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(tt.n, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := padLeft(5, "ab", "x"); err == nil {
+		t.Errorf("expected error for multi character padding")
+	}
+}
+
+func TestSubstr(t *testing.T) {
+	tests := []struct {
+		start, end int
+		input      string
+		expected   string
+	}{
+		{0, 3, "Árvíztűrő", "Árv"},
+		{5, 7, "Árvíztűrő", "tű"},
+		{-3, 100, "Árvíztűrő", "űrő"},
+		{2, 1, "abc", ""},
+		{0, 2, "日本語", "日本"},
+	}
+
+	for _, tt := range tests {
+		if result := substr(tt.start, tt.end, tt.input); result != tt.expected {
+			t.Errorf("substr(%d, %d, %q): expected %q, got %q", tt.start, tt.end, tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestWidthTemplate(t *testing.T) {
+	var buf strings.Builder
+	tpl := `{{ range .names }}|{{ padRight 8 . }}|{{ padLeft 4 (len "runes" .) }}|
+{{ end }}`
+	data := map[string]any{"names": []any{"Ádám", "Zsófia"}}
+	if err := executeTemplate(&buf, tpl, data, renderConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "|Ádám    |   4|\n|Zsófia  |   6|\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}